@@ -0,0 +1,93 @@
+package adapter
+
+import (
+	"errors"
+	"time"
+
+	"chain-of-responsibility-example/domain"
+)
+
+// Handler processes a patient: it is the rest of the chain from one point on.
+// An error stops the visit.
+type Handler func(p *domain.Patient) error
+
+// Middleware is one department's work. It receives the rest of the chain as
+// next and decides when to call it; returning without calling next (usually
+// with domain.Halt) stops the visit.
+type Middleware func(next Handler) Handler
+
+// Step is the part a department implements itself.
+type Step struct {
+	Run     func(p *domain.Patient) error // Does the work; may add notes to the patient's record
+	Skipped string                        // Logged instead of Run when the step is already done
+}
+
+// Once turns a step into a Middleware that runs it only if the patient's
+// record does not show it done yet, and then forwards the patient unless the
+// step failed. The step is recorded under name: in progress while it runs,
+// done when it succeeds.
+func Once(name string, logger domain.Logger, step Step) Middleware {
+	return func(next Handler) Handler {
+		return func(p *domain.Patient) error {
+			if p.Record.Done(name) {
+				logger.Log(step.Skipped)
+				return next(p)
+			}
+			p.Record.Set(name, domain.StepInProgress, time.Now())
+			if err := step.Run(p); err != nil {
+				return err
+			}
+			p.Record.Set(name, domain.StepDone, time.Now())
+			return next(p)
+		}
+	}
+}
+
+// BaseDepartment implements domain.Department around a Middleware, so
+// departments do not repeat the next field, SetNext and the forwarding.
+// Errors are reported as *domain.DepartmentError naming the department
+// that returned them.
+type BaseDepartment struct {
+	name   string
+	handle Middleware
+	next   domain.Department
+}
+
+// NewBaseDepartment builds the department part of a handler.
+func NewBaseDepartment(name string, handle Middleware) BaseDepartment {
+	return BaseDepartment{name: name, handle: handle}
+}
+
+// NewStepDepartment builds a department that runs step once per patient.
+func NewStepDepartment(name string, logger domain.Logger, step Step) BaseDepartment {
+	return NewBaseDepartment(name, Once(name, logger, step))
+}
+
+// Name returns the department name used in errors.
+func (b *BaseDepartment) Name() string {
+	return b.name
+}
+
+func (b *BaseDepartment) Execute(p *domain.Patient) error {
+	err := b.handle(b.forward)(p)
+	if err == nil {
+		return nil
+	}
+	// An error from further down the chain already names its department
+	var deptErr *domain.DepartmentError
+	if errors.As(err, &deptErr) {
+		return err
+	}
+	return &domain.DepartmentError{Department: b.name, Err: err}
+}
+
+func (b *BaseDepartment) SetNext(next domain.Department) {
+	b.next = next
+}
+
+func (b *BaseDepartment) forward(p *domain.Patient) error {
+	if b.next == nil {
+		return nil
+	}
+	return b.next.Execute(p)
+}
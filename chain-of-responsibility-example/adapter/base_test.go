@@ -0,0 +1,97 @@
+package adapter_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"chain-of-responsibility-example/adapter"
+	"chain-of-responsibility-example/domain"
+)
+
+func TestBaseDepartment_Middleware(t *testing.T) {
+	logger := &MockLogger{}
+
+	// A custom department only supplies its own step
+	around := adapter.NewBaseDepartment("around", func(next adapter.Handler) adapter.Handler {
+		return func(p *domain.Patient) error {
+			logger.Log("before " + p.Name)
+			err := next(p)
+			logger.Log("after " + p.Name)
+			return err
+		}
+	})
+	closed := adapter.NewBaseDepartment("closed", func(next adapter.Handler) adapter.Handler {
+		return func(p *domain.Patient) error {
+			logger.Log("closed, sending " + p.Name + " home")
+			return domain.Halt("department closed")
+		}
+	})
+	cashier := adapter.NewCashier(logger)
+
+	around.SetNext(&closed)
+	closed.SetNext(cashier)
+	patient := &domain.Patient{Name: "TestPatient"}
+	err := around.Execute(patient)
+
+	// The error names the department that returned it, not the first one
+	var deptErr *domain.DepartmentError
+	if !errors.As(err, &deptErr) || deptErr.Department != "closed" || !errors.Is(err, domain.ErrVisitHalted) {
+		t.Errorf("error = %v, want a halt from closed", err)
+	}
+	want := []string{"before TestPatient", "closed, sending TestPatient home", "after TestPatient"}
+	if !slices.Equal(logger.Logs, want) {
+		t.Errorf("logs = %q, want %q", logger.Logs, want)
+	}
+	if patient.Record.Done(adapter.DeptCashier) {
+		t.Error("a middleware that does not call next must stop the chain")
+	}
+}
+
+func TestOnce(t *testing.T) {
+	errFailed := errors.New("step failed")
+
+	tests := []struct {
+		name          string
+		done          bool
+		runErr        error
+		wantRuns      int
+		wantForwarded int
+		wantErr       error
+		wantLogs      []string
+		wantStatus    domain.StepStatus
+	}{
+		{"Runs And Forwards", false, nil, 1, 1, nil, nil, domain.StepDone},
+		{"Already Done", true, nil, 0, 1, nil, []string{"already done"}, domain.StepDone},
+		{"Failure Stops", false, errFailed, 1, 0, errFailed, nil, domain.StepInProgress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &MockLogger{}
+			runs, forwarded := 0, 0
+			handle := adapter.Once("xray", logger, adapter.Step{
+				Run:     func(p *domain.Patient) error { runs++; return tt.runErr },
+				Skipped: "already done",
+			})(func(p *domain.Patient) error { forwarded++; return nil })
+
+			patient := &domain.Patient{}
+			if tt.done {
+				patient.Record.Set("xray", domain.StepDone, time.Now())
+			}
+			if err := handle(patient); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if runs != tt.wantRuns || forwarded != tt.wantForwarded {
+				t.Errorf("runs = %d, forwarded = %d, want %d and %d", runs, forwarded, tt.wantRuns, tt.wantForwarded)
+			}
+			if !slices.Equal(logger.Logs, tt.wantLogs) {
+				t.Errorf("logs = %q, want %q", logger.Logs, tt.wantLogs)
+			}
+			if rec, _ := patient.Record.Get("xray"); rec.Status != tt.wantStatus || rec.At.IsZero() {
+				t.Errorf("record = %+v, want status %s", rec, tt.wantStatus)
+			}
+		})
+	}
+}
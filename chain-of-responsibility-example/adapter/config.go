@@ -0,0 +1,105 @@
+package adapter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"chain-of-responsibility-example/domain"
+)
+
+// LoadPipelineConfig reads the department list from a JSON or YAML file,
+// chosen by extension (.json, .yaml, .yml).
+//
+// JSON:
+//
+//	{"departments": ["reception", "doctor", "cashier"]}
+//
+// YAML (a block or flow list under "departments"):
+//
+//	departments:
+//	  - reception
+//	  - doctor
+//	  - cashier
+func LoadPipelineConfig(path string) (domain.PipelineConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return domain.PipelineConfig{}, err
+	}
+
+	var cfg domain.PipelineConfig
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(data, &cfg)
+	case ".yaml", ".yml":
+		cfg, err = parsePipelineYAML(data)
+	default:
+		err = fmt.Errorf("unsupported config format %q", ext)
+	}
+	if err != nil {
+		return domain.PipelineConfig{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// parsePipelineYAML understands the small subset of YAML a pipeline needs,
+// so the example does not pull in a YAML library.
+func parsePipelineYAML(data []byte) (domain.PipelineConfig, error) {
+	var cfg domain.PipelineConfig
+	inList := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := stripYAMLComment(scanner.Text())
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+
+		if key, value, ok := strings.Cut(trimmed, ":"); ok && line == strings.TrimLeft(line, " \t") {
+			if strings.TrimSpace(key) != "departments" {
+				return cfg, fmt.Errorf("line %d: unknown key %q", lineNo, strings.TrimSpace(key))
+			}
+			value = strings.TrimSpace(value)
+			if value == "" {
+				inList = true
+				continue
+			}
+			if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+				return cfg, fmt.Errorf("line %d: departments must be a list", lineNo)
+			}
+			for _, item := range strings.Split(value[1:len(value)-1], ",") {
+				if item = unquoteYAML(item); item != "" {
+					cfg.Departments = append(cfg.Departments, item)
+				}
+			}
+			inList = false
+			continue
+		}
+
+		item, ok := strings.CutPrefix(trimmed, "- ")
+		if !inList || !ok {
+			return cfg, fmt.Errorf("line %d: unexpected %q", lineNo, trimmed)
+		}
+		cfg.Departments = append(cfg.Departments, unquoteYAML(item))
+	}
+	return cfg, scanner.Err()
+}
+
+func stripYAMLComment(line string) string {
+	if i := strings.Index(line, "#"); i == 0 || (i > 0 && (line[i-1] == ' ' || line[i-1] == '\t')) {
+		return line[:i]
+	}
+	return line
+}
+
+func unquoteYAML(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,51 @@
+package adapter_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"chain-of-responsibility-example/adapter"
+)
+
+func TestLoadPipelineConfig(t *testing.T) {
+	want := []string{"reception", "doctor", "lab", "cashier"}
+
+	tests := []struct {
+		name    string
+		file    string
+		data    string
+		want    []string
+		wantErr bool
+	}{
+		{"JSON", "pipeline.json", `{"departments": ["reception", "doctor", "lab", "cashier"]}`, want, false},
+		{"YAML Block List", "pipeline.yaml", "# visit\ndepartments:\n  - reception\n  - doctor   # checkup\n  - \"lab\"\n  - 'cashier'\n", want, false},
+		{"YAML Flow List", "pipeline.yml", "departments: [reception, doctor, lab, cashier]\n", want, false},
+		{"YAML Unknown Key", "pipeline.yaml", "steps:\n  - reception\n", nil, true},
+		{"YAML Item Without Key", "pipeline.yaml", "- reception\n", nil, true},
+		{"Broken JSON", "pipeline.json", `{"departments": [`, nil, true},
+		{"Unsupported Format", "pipeline.toml", `departments = ["reception"]`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := adapter.LoadPipelineConfig(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadPipelineConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(cfg.Departments, tt.want) {
+				t.Errorf("Departments = %v, want %v", cfg.Departments, tt.want)
+			}
+		})
+	}
+
+	if _, err := adapter.LoadPipelineConfig(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file error = %v, want ErrNotExist", err)
+	}
+}
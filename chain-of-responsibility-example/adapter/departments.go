@@ -4,98 +4,110 @@ import (
 	"chain-of-responsibility-example/domain"
 )
 
+// Ensure that the departments implement the chain interface.
+var (
+	_ domain.Department = (*Reception)(nil)
+	_ domain.Department = (*Doctor)(nil)
+	_ domain.Department = (*Lab)(nil)
+	_ domain.Department = (*Pharmacy)(nil)
+	_ domain.Department = (*Cashier)(nil)
+)
+
 // --- Reception ---
 
 // Reception registers the patient and passes to the next department.
 type Reception struct {
-	next   domain.Department
-	logger domain.Logger
+	BaseDepartment
 }
 
 // NewReception builds a reception department.
 func NewReception(logger domain.Logger) *Reception {
-	return &Reception{logger: logger}
-}
-
-func (r *Reception) Execute(p *domain.Patient) {
-	if p.RegistrationDone {
-		r.logger.Log("Patient registration already done")
-		if r.next != nil {
-			r.next.Execute(p)
-		}
-		return
-	}
-	r.logger.Log("Reception registering patient")
-	p.RegistrationDone = true
-	if r.next != nil {
-		r.next.Execute(p)
-	}
-}
-
-func (r *Reception) SetNext(next domain.Department) {
-	r.next = next
+	return &Reception{NewStepDepartment(DeptReception, logger, Step{
+		Run: func(p *domain.Patient) error {
+			logger.Log("Reception registering patient")
+			p.Record.Note(DeptReception, "triage: "+p.Triage.String())
+			return nil
+		},
+		Skipped: "Patient registration already done",
+	})}
 }
 
 // --- Doctor ---
 
 // Doctor performs the checkup and passes to the next department.
 type Doctor struct {
-	next   domain.Department
-	logger domain.Logger
+	BaseDepartment
 }
 
 // NewDoctor builds a doctor department.
 func NewDoctor(logger domain.Logger) *Doctor {
-	return &Doctor{logger: logger}
+	return &Doctor{NewStepDepartment(DeptDoctor, logger, Step{
+		Run: func(p *domain.Patient) error {
+			logger.Log("Doctor checking patient")
+			if p.NeedsLabTest {
+				p.Record.Note(DeptDoctor, "lab tests ordered")
+			}
+			return nil
+		},
+		Skipped: "Doctor checkup already done",
+	})}
+}
+
+// --- Lab ---
+
+// Lab runs the tests the doctor ordered and passes to the next department.
+type Lab struct {
+	BaseDepartment
+}
+
+// NewLab builds a lab department.
+func NewLab(logger domain.Logger) *Lab {
+	return &Lab{NewStepDepartment(DeptLab, logger, Step{
+		Run: func(p *domain.Patient) error {
+			logger.Log("Lab running tests")
+			return nil
+		},
+		Skipped: "Lab tests already done",
+	})}
 }
 
-func (d *Doctor) Execute(p *domain.Patient) {
-	if p.DoctorCheckUpDone {
-		d.logger.Log("Doctor checkup already done")
-		if d.next != nil {
-			d.next.Execute(p)
-		}
-		return
-	}
-	d.logger.Log("Doctor checking patient")
-	p.DoctorCheckUpDone = true
-	if d.next != nil {
-		d.next.Execute(p)
-	}
+// --- Pharmacy ---
+
+// Pharmacy hands out the prescribed medicine and passes to the next department.
+type Pharmacy struct {
+	BaseDepartment
 }
 
-func (d *Doctor) SetNext(next domain.Department) {
-	d.next = next
+// NewPharmacy builds a pharmacy department.
+func NewPharmacy(logger domain.Logger) *Pharmacy {
+	return &Pharmacy{NewStepDepartment(DeptPharmacy, logger, Step{
+		Run: func(p *domain.Patient) error {
+			logger.Log("Pharmacy handing out medicine")
+			return nil
+		},
+		Skipped: "Medicine already handed out",
+	})}
 }
 
 // --- Cashier ---
 
 // Cashier handles payments and passes to the next department.
+// A declined payment stops the visit.
 type Cashier struct {
-	next   domain.Department
-	logger domain.Logger
+	BaseDepartment
 }
 
 // NewCashier builds a cashier department.
 func NewCashier(logger domain.Logger) *Cashier {
-	return &Cashier{logger: logger}
-}
-
-func (c *Cashier) Execute(p *domain.Patient) {
-	if p.PaymentDone {
-		c.logger.Log("Payment already done")
-		if c.next != nil {
-			c.next.Execute(p)
-		}
-		return
-	}
-	c.logger.Log("Cashier getting money")
-	p.PaymentDone = true
-	if c.next != nil {
-		c.next.Execute(p)
-	}
-}
-
-func (c *Cashier) SetNext(next domain.Department) {
-	c.next = next
+	return &Cashier{NewStepDepartment(DeptCashier, logger, Step{
+		Run: func(p *domain.Patient) error {
+			if p.PaymentDeclined {
+				logger.Log("Cashier could not get money")
+				return domain.Halt("payment declined")
+			}
+			logger.Log("Cashier getting money")
+			return nil
+		},
+		Skipped: "Payment already done",
+	})}
 }
@@ -1,6 +1,8 @@
 package adapter_test
 
 import (
+	"errors"
+	"slices"
 	"testing"
 
 	"chain-of-responsibility-example/adapter"
@@ -29,17 +31,15 @@ func TestHospitalChain(t *testing.T) {
 	patient := &domain.Patient{Name: "TestPatient"}
 
 	// Run
-	reception.Execute(patient)
+	if err := reception.Execute(patient); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	// Verify Patient State
-	if !patient.RegistrationDone {
-		t.Error("Registration not done")
-	}
-	if !patient.DoctorCheckUpDone {
-		t.Error("Doctor checkup not done")
-	}
-	if !patient.PaymentDone {
-		t.Error("Payment not done")
+	for _, step := range []string{adapter.DeptReception, adapter.DeptDoctor, adapter.DeptCashier} {
+		if !patient.Record.Done(step) {
+			t.Errorf("%s not done", step)
+		}
 	}
 
 	// Verify Logs
@@ -61,7 +61,9 @@ func TestHospitalChain(t *testing.T) {
 
 	// Run again (Already done)
 	logger.Logs = nil // clear logs
-	reception.Execute(patient)
+	if err := reception.Execute(patient); err != nil {
+		t.Fatalf("unexpected error (2nd run): %v", err)
+	}
 
 	expectedLogsDone := []string{
 		"Patient registration already done",
@@ -79,3 +81,74 @@ func TestHospitalChain(t *testing.T) {
 		}
 	}
 }
+
+func TestHospitalChain_Halts(t *testing.T) {
+	tests := []struct {
+		name          string
+		patient       domain.Patient
+		withReception bool
+		wantDept      string
+		wantLogs      []string
+	}{
+		{
+			name:          "Payment Declined",
+			patient:       domain.Patient{Name: "TestPatient", PaymentDeclined: true},
+			withReception: true,
+			wantDept:      "cashier",
+			wantLogs:      []string{"Reception registering patient", "Doctor checking patient", "Cashier could not get money"},
+		},
+		{
+			name:     "Payment Declined Without Reception",
+			patient:  domain.Patient{Name: "TestPatient", PaymentDeclined: true},
+			wantDept: "cashier",
+			wantLogs: []string{"Doctor checking patient", "Cashier could not get money"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &MockLogger{}
+			doctor := adapter.NewDoctor(logger)
+			doctor.SetNext(adapter.NewCashier(logger))
+			var head domain.Department = doctor
+			if tt.withReception {
+				reception := adapter.NewReception(logger)
+				reception.SetNext(doctor)
+				head = reception
+			}
+
+			patient := tt.patient
+			err := head.Execute(&patient)
+
+			var deptErr *domain.DepartmentError
+			if !errors.As(err, &deptErr) || deptErr.Department != tt.wantDept || !errors.Is(err, domain.ErrVisitHalted) {
+				t.Fatalf("error = %v, want a halt from %s", err, tt.wantDept)
+			}
+			if patient.Record.Done(adapter.DeptCashier) {
+				t.Error("payment must not be recorded")
+			}
+			if !slices.Equal(logger.Logs, tt.wantLogs) {
+				t.Errorf("logs = %q, want %q", logger.Logs, tt.wantLogs)
+			}
+		})
+	}
+}
+
+func TestHospitalChain_StartsAtDoctor(t *testing.T) {
+	logger := &MockLogger{}
+	doctor := adapter.NewDoctor(logger)
+	doctor.SetNext(adapter.NewCashier(logger))
+
+	// A chain without reception still examines the patient
+	patient := &domain.Patient{Name: "TestPatient"}
+	if err := doctor.Execute(patient); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"Doctor checking patient", "Cashier getting money"}
+	if !slices.Equal(logger.Logs, want) {
+		t.Errorf("logs = %q, want %q", logger.Logs, want)
+	}
+	if patient.Record.Done(adapter.DeptReception) || !patient.Record.Done(adapter.DeptDoctor) {
+		t.Errorf("unexpected record %+v", patient.Record.Steps())
+	}
+}
@@ -13,3 +13,8 @@ func NewConsoleLogger() *ConsoleLogger {
 func (l *ConsoleLogger) Log(message string) {
 	fmt.Println(message)
 }
+
+// NopLogger discards logs. It is safe for concurrent use.
+type NopLogger struct{}
+
+func (NopLogger) Log(string) {}
@@ -0,0 +1,63 @@
+package adapter
+
+import (
+	"chain-of-responsibility-example/domain"
+	"chain-of-responsibility-example/usecase"
+)
+
+// Names under which the departments are registered.
+const (
+	DeptReception = "reception"
+	DeptDoctor    = "doctor"
+	DeptLab       = "lab"
+	DeptPharmacy  = "pharmacy"
+	DeptCashier   = "cashier"
+)
+
+// DefaultPipeline is the usual visit: Reception -> Doctor -> Cashier.
+var DefaultPipeline = []string{DeptReception, DeptDoctor, DeptCashier}
+
+// RegisterDepartments registers every department in b.
+func RegisterDepartments(b *usecase.PipelineBuilder, logger domain.Logger) {
+	b.Register(DeptReception, func() domain.Department { return NewReception(logger) })
+	b.Register(DeptDoctor, func() domain.Department { return NewDoctor(logger) })
+	b.Register(DeptLab, func() domain.Department { return NewLab(logger) })
+	b.Register(DeptPharmacy, func() domain.Department { return NewPharmacy(logger) })
+	b.Register(DeptCashier, func() domain.Department { return NewCashier(logger) })
+}
+
+// NewDefaultPipelineBuilder returns a builder with every department registered.
+func NewDefaultPipelineBuilder(logger domain.Logger) *usecase.PipelineBuilder {
+	b := usecase.NewPipelineBuilder()
+	RegisterDepartments(b, logger)
+	return b
+}
+
+// NewRoutedPipeline builds a visit whose route depends on the patient:
+//
+//	triage --(emergency)--------------+
+//	  |                               v
+//	  +--> Reception -------------> Doctor --> lab order --(lab test)--> Lab --+
+//	                                               |                           |
+//	                                               +<--------------------------+
+//	                                               v
+//	                                            billing --(insured)--> done
+//	                                               |
+//	                                               +--> Cashier
+func NewRoutedPipeline(logger domain.Logger) domain.Department {
+	reception := NewReception(logger)
+	doctor := NewDoctor(logger)
+	lab := NewLab(logger)
+	cashier := NewCashier(logger)
+
+	triage := NewRouter("triage", logger, Route{Name: "emergency, skipping reception", When: IsEmergency, To: doctor})
+	labOrder := NewRouter("lab order", logger, Route{Name: "tests ordered, sending to lab", When: NeedsLabTest, To: lab, Rejoin: true})
+	billing := NewRouter("billing", logger, Route{Name: "insured, skipping cashier", When: IsInsured})
+
+	triage.SetNext(reception)
+	reception.SetNext(doctor)
+	doctor.SetNext(labOrder)
+	labOrder.SetNext(billing) // Also links lab -> billing
+	billing.SetNext(cashier)
+	return triage
+}
@@ -0,0 +1,33 @@
+package adapter_test
+
+import (
+	"slices"
+	"testing"
+
+	"chain-of-responsibility-example/adapter"
+	"chain-of-responsibility-example/domain"
+)
+
+func TestDefaultPipelineBuilder(t *testing.T) {
+	logger := &MockLogger{}
+	head, err := adapter.NewDefaultPipelineBuilder(logger).Build([]string{
+		adapter.DeptReception, adapter.DeptDoctor, adapter.DeptLab, adapter.DeptPharmacy, adapter.DeptCashier,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := head.Execute(&domain.Patient{Name: "TestPatient"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"Reception registering patient",
+		"Doctor checking patient",
+		"Lab running tests",
+		"Pharmacy handing out medicine",
+		"Cashier getting money",
+	}
+	if !slices.Equal(logger.Logs, want) {
+		t.Errorf("logs = %q, want %q", logger.Logs, want)
+	}
+}
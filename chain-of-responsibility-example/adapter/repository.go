@@ -0,0 +1,128 @@
+package adapter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"chain-of-responsibility-example/domain"
+)
+
+// MemoryVisitRepository keeps visits in memory. Patients are stored as JSON,
+// so a loaded patient never shares its record with the saved one.
+type MemoryVisitRepository struct {
+	mu     sync.Mutex
+	visits map[string][]byte
+}
+
+var _ domain.VisitRepository = (*MemoryVisitRepository)(nil)
+
+// NewMemoryVisitRepository creates an empty repository.
+func NewMemoryVisitRepository() *MemoryVisitRepository {
+	return &MemoryVisitRepository{visits: make(map[string][]byte)}
+}
+
+func (r *MemoryVisitRepository) Save(p *domain.Patient) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.visits[p.Name] = data
+	return nil
+}
+
+func (r *MemoryVisitRepository) Load(name string) (*domain.Patient, error) {
+	r.mu.Lock()
+	data, ok := r.visits[name]
+	r.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrVisitNotFound, name)
+	}
+	var p domain.Patient
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// JSONFileVisitRepository keeps all visits in one JSON file, keyed by
+// patient name. Every Save rewrites the file through a temporary file and a
+// rename, so a crash never leaves a half-written file behind.
+type JSONFileVisitRepository struct {
+	mu   sync.Mutex
+	path string
+}
+
+var _ domain.VisitRepository = (*JSONFileVisitRepository)(nil)
+
+// NewJSONFileVisitRepository uses the file at path, which is created on the
+// first Save.
+func NewJSONFileVisitRepository(path string) *JSONFileVisitRepository {
+	return &JSONFileVisitRepository{path: path}
+}
+
+func (r *JSONFileVisitRepository) Save(p *domain.Patient) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	visits, err := r.read()
+	if err != nil {
+		return err
+	}
+	visits[p.Name] = p
+	data, err := json.MarshalIndent(visits, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
+
+func (r *JSONFileVisitRepository) Load(name string) (*domain.Patient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	visits, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	p, ok := visits[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrVisitNotFound, name)
+	}
+	return p, nil
+}
+
+// read returns the visits in the file; a missing file holds none.
+func (r *JSONFileVisitRepository) read() (map[string]*domain.Patient, error) {
+	visits := make(map[string]*domain.Patient)
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return visits, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &visits); err != nil {
+		return nil, fmt.Errorf("%s: %w", r.path, err)
+	}
+	return visits, nil
+}
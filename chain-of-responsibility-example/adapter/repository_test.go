@@ -0,0 +1,145 @@
+package adapter_test
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"chain-of-responsibility-example/adapter"
+	"chain-of-responsibility-example/domain"
+	"chain-of-responsibility-example/usecase"
+)
+
+func TestVisitRepositories(t *testing.T) {
+	tests := []struct {
+		name string
+		open func(t *testing.T) (domain.VisitRepository, func() domain.VisitRepository)
+	}{
+		{"Memory", func(t *testing.T) (domain.VisitRepository, func() domain.VisitRepository) {
+			repo := adapter.NewMemoryVisitRepository()
+			return repo, func() domain.VisitRepository { return repo }
+		}},
+		{"JSON File", func(t *testing.T) (domain.VisitRepository, func() domain.VisitRepository) {
+			path := filepath.Join(t.TempDir(), "visits.json")
+			// Reopening reads the file again, as a restarted process would
+			return adapter.NewJSONFileVisitRepository(path), func() domain.VisitRepository {
+				return adapter.NewJSONFileVisitRepository(path)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, reopen := tt.open(t)
+			if _, err := repo.Load("abc"); !errors.Is(err, domain.ErrVisitNotFound) {
+				t.Errorf("Load() error = %v, want ErrVisitNotFound", err)
+			}
+
+			at := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+			patient := &domain.Patient{Name: "abc", Triage: domain.TriageUrgent, NeedsLabTest: true}
+			patient.Record.Set(adapter.DeptReception, domain.StepDone, at)
+			patient.Record.Note(adapter.DeptReception, "triage: urgent")
+			if err := repo.Save(patient); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			if err := repo.Save(&domain.Patient{Name: "def"}); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			// Later changes are not seen until the patient is saved again
+			patient.Record.Set(adapter.DeptDoctor, domain.StepInProgress, at)
+
+			loaded, err := reopen().Load("abc")
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if loaded.Name != "abc" || loaded.Triage != domain.TriageUrgent || !loaded.NeedsLabTest {
+				t.Errorf("loaded %+v", loaded)
+			}
+			steps := loaded.Record.Steps()
+			if len(steps) != 1 || !steps[0].At.Equal(at) || !slices.Equal(steps[0].Notes, []string{"triage: urgent"}) {
+				t.Errorf("loaded steps = %+v", steps)
+			}
+
+			loaded.Record.Note(adapter.DeptReception, "changed")
+			again, _ := repo.Load("abc")
+			if rec, _ := again.Record.Get(adapter.DeptReception); len(rec.Notes) != 1 {
+				t.Errorf("a loaded patient shares its record with the repository: %+v", rec)
+			}
+		})
+	}
+}
+
+// CrashingRepository panics right after a number of saves, as if the process died
+type CrashingRepository struct {
+	domain.VisitRepository
+	Saves      int
+	CrashAfter int
+}
+
+func (r *CrashingRepository) Save(p *domain.Patient) error {
+	if err := r.VisitRepository.Save(p); err != nil {
+		return err
+	}
+	r.Saves++
+	if r.Saves == r.CrashAfter {
+		panic("crash")
+	}
+	return nil
+}
+
+func TestResumableVisit_CrashAfterEachStep(t *testing.T) {
+	names := []string{adapter.DeptReception, adapter.DeptDoctor, adapter.DeptLab, adapter.DeptPharmacy, adapter.DeptCashier}
+	wantLogs := []string{
+		"Reception registering patient",
+		"Doctor checking patient",
+		"Lab running tests",
+		"Pharmacy handing out medicine",
+		"Cashier getting money",
+	}
+
+	// The first save is before any step, then one after each department
+	for crashAfter := 1; crashAfter <= len(names)+1; crashAfter++ {
+		name := "Before " + names[0]
+		if crashAfter > 1 {
+			name = "After " + names[crashAfter-2]
+		}
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "visits.json")
+			logger := &MockLogger{}
+
+			crashing := &CrashingRepository{VisitRepository: adapter.NewJSONFileVisitRepository(path), CrashAfter: crashAfter}
+			first, err := usecase.NewResumableVisitService(adapter.NewDefaultPipelineBuilder(logger), names, crashing)
+			if err != nil {
+				t.Fatal(err)
+			}
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Fatal("the visit did not crash")
+					}
+				}()
+				first.Visit(&domain.Patient{Name: "abc"})
+			}()
+
+			// After a restart, only the departments that had not finished run
+			second, err := usecase.NewResumableVisitService(adapter.NewDefaultPipelineBuilder(logger), names, adapter.NewJSONFileVisitRepository(path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			patient, result, err := second.Resume("abc")
+			if err != nil || !result.Completed {
+				t.Fatalf("Resume() = %+v, %v", result, err)
+			}
+			if !slices.Equal(logger.Logs, wantLogs) {
+				t.Errorf("logs = %q, want %q", logger.Logs, wantLogs)
+			}
+			for _, name := range names {
+				if !patient.Record.Done(name) {
+					t.Errorf("%s is not done", name)
+				}
+			}
+		})
+	}
+}
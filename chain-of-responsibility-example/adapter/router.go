@@ -0,0 +1,73 @@
+package adapter
+
+import (
+	"fmt"
+	"time"
+
+	"chain-of-responsibility-example/domain"
+)
+
+// Ensure implementation
+var _ domain.Department = (*Router)(nil)
+
+// Predicate tells whether a route applies to the patient.
+type Predicate func(p *domain.Patient) bool
+
+// Common predicates
+var (
+	IsEmergency  Predicate = func(p *domain.Patient) bool { return p.Triage == domain.TriageEmergency }
+	IsInsured    Predicate = func(p *domain.Patient) bool { return p.Insured }
+	NeedsLabTest Predicate = func(p *domain.Patient) bool { return p.NeedsLabTest }
+)
+
+// Route sends the patients matching When to To instead of the next department.
+// A nil To ends the visit there. With Rejoin, To is a detour: when the router
+// is linked, To is linked to the same next department, so the patient comes
+// back to the main line afterwards.
+type Route struct {
+	Name   string
+	When   Predicate
+	To     domain.Department
+	Rejoin bool
+}
+
+// Router is a department that does no work itself; it picks the next
+// handler from the first route whose predicate matches, or forwards to its
+// next department when none does. A route taken is noted in the patient's
+// record under the router's name.
+type Router struct {
+	BaseDepartment
+	routes []Route
+}
+
+// NewRouter builds a router that tries routes in order.
+func NewRouter(name string, logger domain.Logger, routes ...Route) *Router {
+	r := &Router{routes: routes}
+	r.BaseDepartment = NewBaseDepartment(name, func(next Handler) Handler {
+		return func(p *domain.Patient) error {
+			for _, route := range r.routes {
+				if !route.When(p) {
+					continue
+				}
+				logger.Log(fmt.Sprintf("Routing %s: %s", name, route.Name))
+				p.Record.Set(name, domain.StepDone, time.Now())
+				p.Record.Note(name, route.Name)
+				if route.To == nil {
+					return nil
+				}
+				return route.To.Execute(p)
+			}
+			return next(p)
+		}
+	})
+	return r
+}
+
+func (r *Router) SetNext(next domain.Department) {
+	r.BaseDepartment.SetNext(next)
+	for _, route := range r.routes {
+		if route.Rejoin && route.To != nil {
+			route.To.SetNext(next)
+		}
+	}
+}
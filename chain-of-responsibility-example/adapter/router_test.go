@@ -0,0 +1,135 @@
+package adapter_test
+
+import (
+	"slices"
+	"testing"
+
+	"chain-of-responsibility-example/adapter"
+	"chain-of-responsibility-example/domain"
+)
+
+func TestRoutedPipeline(t *testing.T) {
+	tests := []struct {
+		name     string
+		patient  domain.Patient
+		wantLogs []string
+	}{
+		{
+			name:    "Routine",
+			patient: domain.Patient{Name: "P"},
+			wantLogs: []string{
+				"Reception registering patient",
+				"Doctor checking patient",
+				"Cashier getting money",
+			},
+		},
+		{
+			name:    "Emergency Skips Reception",
+			patient: domain.Patient{Name: "P", Triage: domain.TriageEmergency},
+			wantLogs: []string{
+				"Routing triage: emergency, skipping reception",
+				"Doctor checking patient",
+				"Cashier getting money",
+			},
+		},
+		{
+			name:    "Urgent Still Registers",
+			patient: domain.Patient{Name: "P", Triage: domain.TriageUrgent},
+			wantLogs: []string{
+				"Reception registering patient",
+				"Doctor checking patient",
+				"Cashier getting money",
+			},
+		},
+		{
+			name:    "Insured Skips Cashier",
+			patient: domain.Patient{Name: "P", Insured: true},
+			wantLogs: []string{
+				"Reception registering patient",
+				"Doctor checking patient",
+				"Routing billing: insured, skipping cashier",
+			},
+		},
+		{
+			name:    "Lab Branch Comes Back",
+			patient: domain.Patient{Name: "P", NeedsLabTest: true},
+			wantLogs: []string{
+				"Reception registering patient",
+				"Doctor checking patient",
+				"Routing lab order: tests ordered, sending to lab",
+				"Lab running tests",
+				"Cashier getting money",
+			},
+		},
+		{
+			name:    "All Routes",
+			patient: domain.Patient{Name: "P", Triage: domain.TriageEmergency, Insured: true, NeedsLabTest: true},
+			wantLogs: []string{
+				"Routing triage: emergency, skipping reception",
+				"Doctor checking patient",
+				"Routing lab order: tests ordered, sending to lab",
+				"Lab running tests",
+				"Routing billing: insured, skipping cashier",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &MockLogger{}
+			head := adapter.NewRoutedPipeline(logger)
+
+			patient := tt.patient
+			if err := head.Execute(&patient); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(logger.Logs, tt.wantLogs) {
+				t.Errorf("logs = %q, want %q", logger.Logs, tt.wantLogs)
+			}
+		})
+	}
+}
+
+func TestRouter_FirstMatchingRouteWins(t *testing.T) {
+	logger := &MockLogger{}
+	lab := adapter.NewLab(logger)
+	cashier := adapter.NewCashier(logger)
+	router := adapter.NewRouter("test", logger,
+		adapter.Route{Name: "never", When: func(p *domain.Patient) bool { return false }, To: cashier},
+		adapter.Route{Name: "lab", When: adapter.NeedsLabTest, To: lab},
+		adapter.Route{Name: "insured", When: adapter.IsInsured, To: cashier},
+	)
+	router.SetNext(cashier)
+
+	patient := &domain.Patient{Name: "P", NeedsLabTest: true, Insured: true}
+	if err := router.Execute(patient); err != nil {
+		t.Fatal(err)
+	}
+	// Without Rejoin the lab does not come back to the cashier
+	want := []string{"Routing test: lab", "Lab running tests"}
+	if !slices.Equal(logger.Logs, want) {
+		t.Errorf("logs = %q, want %q", logger.Logs, want)
+	}
+}
+
+func TestRoutedPipeline_Record(t *testing.T) {
+	patient := &domain.Patient{Name: "P", Triage: domain.TriageEmergency, Insured: true, NeedsLabTest: true}
+	if err := adapter.NewRoutedPipeline(&MockLogger{}).Execute(patient); err != nil {
+		t.Fatal(err)
+	}
+
+	var steps []string
+	for _, s := range patient.Record.Steps() {
+		if s.Status != domain.StepDone {
+			t.Errorf("%s: status %s, want done", s.Step, s.Status)
+		}
+		steps = append(steps, s.Step)
+	}
+	want := []string{"triage", adapter.DeptDoctor, "lab order", adapter.DeptLab, "billing"}
+	if !slices.Equal(steps, want) {
+		t.Errorf("steps = %v, want %v", steps, want)
+	}
+	if rec, _ := patient.Record.Get("billing"); !slices.Equal(rec.Notes, []string{"insured, skipping cashier"}) {
+		t.Errorf("billing notes = %q", rec.Notes)
+	}
+}
@@ -1,19 +1,131 @@
 package domain
 
+import (
+	"errors"
+	"fmt"
+)
+
+// Triage is how urgently a patient needs care.
+type Triage int
+
+// Triage levels
+const (
+	TriageRoutine Triage = iota
+	TriageUrgent
+	TriageEmergency
+)
+
+func (t Triage) String() string {
+	switch t {
+	case TriageRoutine:
+		return "routine"
+	case TriageUrgent:
+		return "urgent"
+	case TriageEmergency:
+		return "emergency"
+	}
+	return fmt.Sprintf("Triage(%d)", int(t))
+}
+
 // Patient represents the workflow state as it moves through departments.
+// Triage, Insured and NeedsLabTest decide the route through the hospital,
+// and each department records its step in Record.
 type Patient struct {
-	Name              string
-	RegistrationDone  bool
-	DoctorCheckUpDone bool
-	PaymentDone       bool
+	Name            string      `json:"name"`
+	Triage          Triage      `json:"triage"`
+	Insured         bool        `json:"insured"`          // The insurer pays, so the cashier is skipped
+	NeedsLabTest    bool        `json:"needs_lab_test"`   // The doctor ordered tests
+	PaymentDeclined bool        `json:"payment_declined"` // The patient's card or cash is refused at the cashier
+	Record          VisitRecord `json:"record"`
+}
+
+// AuditTrail returns the steps recorded so far.
+func (p *Patient) AuditTrail() AuditTrail {
+	return AuditTrail{Patient: p.Name, Steps: p.Record.Steps()}
 }
 
 // Department defines the chain interface.
+// Execute handles the patient and passes it on. An error stops the visit:
+// the departments after it are not run.
 type Department interface {
-	Execute(*Patient)
+	Execute(*Patient) error
 	SetNext(Department)
 }
 
+// DepartmentFactory builds a new, unlinked department.
+type DepartmentFactory func() Department
+
+// PipelineConfig lists the departments a patient visits, in order.
+type PipelineConfig struct {
+	Departments []string `json:"departments"`
+}
+
+// Pipeline errors
+var (
+	ErrEmptyPipeline     = errors.New("pipeline has no departments")
+	ErrUnknownDepartment = errors.New("unknown department")
+	ErrDepartmentCycle   = errors.New("department appears more than once in the pipeline")
+)
+
+// ErrVisitHalted matches a HaltError.
+var ErrVisitHalted = errors.New("visit halted")
+
+// HaltError is returned by a department that stops the visit on purpose,
+// e.g. a cashier refusing an unpaid bill.
+type HaltError struct {
+	Reason string
+}
+
+// Halt returns an error that stops the visit for reason.
+func Halt(reason string) error {
+	return &HaltError{Reason: reason}
+}
+
+func (e *HaltError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrVisitHalted, e.Reason)
+}
+
+func (e *HaltError) Is(target error) bool {
+	return target == ErrVisitHalted
+}
+
+// DepartmentError tells which department stopped the visit.
+type DepartmentError struct {
+	Department string
+	Err        error
+}
+
+func (e *DepartmentError) Error() string {
+	return e.Department + ": " + e.Err.Error()
+}
+
+func (e *DepartmentError) Unwrap() error {
+	return e.Err
+}
+
+// VisitResult reports how a visit ended.
+// When the visit did not complete, StoppedBy names the department that
+// stopped it and Reason says why. Halted tells a deliberate stop
+// (see HaltError) from a failure.
+type VisitResult struct {
+	Patient   string
+	Completed bool
+	StoppedBy string
+	Reason    string
+	Halted    bool
+}
+
+// ErrVisitNotFound is returned when no visit is saved for a patient.
+var ErrVisitNotFound = errors.New("visit not found")
+
+// VisitRepository keeps patients between the steps of a visit, so a visit
+// interrupted by a crash can be resumed. Patients are stored by name, and
+// Load returns a copy that does not share state with the saved patient.
+type VisitRepository interface {
+	Save(p *Patient) error
+	Load(name string) (*Patient, error)
+}
+
 // Logger abstracts logging for the domain.
 type Logger interface {
 	Log(message string)
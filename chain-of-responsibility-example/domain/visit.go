@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// StepStatus is how far a department got with a patient.
+type StepStatus string
+
+// Step statuses
+const (
+	StepInProgress StepStatus = "in_progress"
+	StepDone       StepStatus = "done"
+	StepHalted     StepStatus = "halted"
+	StepFailed     StepStatus = "failed"
+)
+
+// StepRecord is what one department recorded about the patient.
+type StepRecord struct {
+	Step   string     `json:"step"`
+	Status StepStatus `json:"status"`
+	At     time.Time  `json:"at"`
+	Notes  []string   `json:"notes,omitempty"`
+}
+
+// VisitRecord tracks the steps of a visit by name, in the order they were
+// first recorded. The zero value is an empty record.
+// It marshals to JSON as an ordered list of steps.
+type VisitRecord struct {
+	order []string
+	steps map[string]*StepRecord
+}
+
+// Get returns the record of step.
+func (r *VisitRecord) Get(step string) (StepRecord, bool) {
+	rec, ok := r.steps[step]
+	if !ok {
+		return StepRecord{}, false
+	}
+	return rec.clone(), true
+}
+
+// Done reports whether step has been completed.
+func (r *VisitRecord) Done(step string) bool {
+	rec, ok := r.steps[step]
+	return ok && rec.Status == StepDone
+}
+
+// Set records the status of step at the given time, keeping earlier notes.
+func (r *VisitRecord) Set(step string, status StepStatus, at time.Time) {
+	rec := r.entry(step)
+	rec.Status = status
+	rec.At = at
+}
+
+// Note appends a note to step, recording the step if needed.
+func (r *VisitRecord) Note(step, note string) {
+	rec := r.entry(step)
+	rec.Notes = append(rec.Notes, note)
+}
+
+// Steps returns the step records in order.
+func (r *VisitRecord) Steps() []StepRecord {
+	steps := make([]StepRecord, 0, len(r.order))
+	for _, name := range r.order {
+		steps = append(steps, r.steps[name].clone())
+	}
+	return steps
+}
+
+func (r *VisitRecord) entry(step string) *StepRecord {
+	if r.steps == nil {
+		r.steps = make(map[string]*StepRecord)
+	}
+	rec, ok := r.steps[step]
+	if !ok {
+		rec = &StepRecord{Step: step}
+		r.steps[step] = rec
+		r.order = append(r.order, step)
+	}
+	return rec
+}
+
+func (r VisitRecord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Steps())
+}
+
+func (r *VisitRecord) UnmarshalJSON(data []byte) error {
+	var steps []StepRecord
+	if err := json.Unmarshal(data, &steps); err != nil {
+		return err
+	}
+	*r = VisitRecord{}
+	for _, s := range steps {
+		rec := r.entry(s.Step)
+		*rec = s
+	}
+	return nil
+}
+
+func (s *StepRecord) clone() StepRecord {
+	c := *s
+	c.Notes = append([]string(nil), s.Notes...)
+	return c
+}
+
+// AuditTrail is the exportable history of a visit.
+type AuditTrail struct {
+	Patient string       `json:"patient"`
+	Steps   []StepRecord `json:"steps"`
+}
@@ -0,0 +1,77 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"chain-of-responsibility-example/domain"
+)
+
+func TestVisitRecord(t *testing.T) {
+	t0 := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+	var r domain.VisitRecord
+
+	if r.Done("reception") || len(r.Steps()) != 0 {
+		t.Fatal("zero record must be empty")
+	}
+
+	r.Set("reception", domain.StepInProgress, t0)
+	r.Set("reception", domain.StepDone, t0.Add(time.Minute))
+	r.Note("doctor", "lab tests ordered")
+	r.Set("doctor", domain.StepDone, t0.Add(10*time.Minute))
+	r.Set("cashier", domain.StepHalted, t0.Add(20*time.Minute))
+	r.Note("cashier", "payment declined")
+
+	want := []domain.StepRecord{
+		{Step: "reception", Status: domain.StepDone, At: t0.Add(time.Minute)},
+		{Step: "doctor", Status: domain.StepDone, At: t0.Add(10 * time.Minute), Notes: []string{"lab tests ordered"}},
+		{Step: "cashier", Status: domain.StepHalted, At: t0.Add(20 * time.Minute), Notes: []string{"payment declined"}},
+	}
+	if got := r.Steps(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Steps() = %+v, want %+v", got, want)
+	}
+	if !r.Done("doctor") || r.Done("cashier") || r.Done("lab") {
+		t.Error("Done() must only be true for completed steps")
+	}
+
+	// Records handed out are copies
+	rec, _ := r.Get("doctor")
+	rec.Notes[0] = "changed"
+	if again, _ := r.Get("doctor"); again.Notes[0] != "lab tests ordered" {
+		t.Error("Get() must not expose internal notes")
+	}
+}
+
+func TestVisitRecord_JSON(t *testing.T) {
+	t0 := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+	p := domain.Patient{Name: "abc"}
+	p.Record.Set("reception", domain.StepDone, t0)
+	p.Record.Set("doctor", domain.StepDone, t0.Add(time.Minute))
+	p.Record.Note("doctor", "lab tests ordered")
+
+	data, err := json.Marshal(p.AuditTrail())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"patient":"abc","steps":[` +
+		`{"step":"reception","status":"done","at":"2025-04-01T09:00:00Z"},` +
+		`{"step":"doctor","status":"done","at":"2025-04-01T09:01:00Z","notes":["lab tests ordered"]}]}`
+	if string(data) != want {
+		t.Errorf("audit trail = %s\nwant %s", data, want)
+	}
+
+	// A patient round-trips with its record, in order
+	data, err = json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restored domain.Patient
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restored.Record.Steps(), p.Record.Steps()) || !restored.Record.Done("doctor") {
+		t.Errorf("restored record = %+v, want %+v", restored.Record.Steps(), p.Record.Steps())
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"chain-of-responsibility-example/adapter"
 	"chain-of-responsibility-example/domain"
 	"chain-of-responsibility-example/usecase"
+	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 )
 
 func main() {
@@ -12,14 +15,15 @@ func main() {
 
 	logger := adapter.NewConsoleLogger()
 
-	// 1. Create Handlers (Adapters)
-	cashier := adapter.NewCashier(logger)
-	doctor := adapter.NewDoctor(logger)
-	reception := adapter.NewReception(logger)
+	// 1. Register Handlers (Adapters) by name
+	builder := adapter.NewDefaultPipelineBuilder(logger)
 
-	// 2. Set Chain: Reception -> Doctor -> Cashier
-	reception.SetNext(doctor)
-	doctor.SetNext(cashier)
+	// 2. Build Chain: Reception -> Doctor -> Cashier
+	reception, err := builder.Build(adapter.DefaultPipeline)
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
+	}
 
 	// 3. Setup Usecase
 	// The usecase knows the "head" of the chain (Reception)
@@ -27,5 +31,136 @@ func main() {
 
 	// 4. Execute
 	patient := &domain.Patient{Name: "abc"}
-	visitService.VisitHospital(patient)
+	visit(visitService, patient)
+
+	// 5. A longer visit loaded from a config file (Lab and Pharmacy inserted)
+	fmt.Println("\n--- Pipeline from pipeline.yaml ---")
+	cfg, err := adapter.LoadPipelineConfig("pipeline.yaml")
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
+	}
+	head, err := builder.BuildFromConfig(cfg)
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
+	}
+	visit(usecase.NewPatientVisitService(head), &domain.Patient{Name: "def"})
+
+	// 6. A department can stop the visit
+	fmt.Println("\n--- Payment declined ---")
+	visit(visitService, &domain.Patient{Name: "ghi", PaymentDeclined: true})
+
+	// 7. The route depends on the patient
+	routed := usecase.NewPatientVisitService(adapter.NewRoutedPipeline(logger))
+	fmt.Println("\n--- Emergency patient with insurance ---")
+	visit(routed, &domain.Patient{Name: "jkl", Triage: domain.TriageEmergency, Insured: true})
+	fmt.Println("\n--- Patient who needs lab tests ---")
+	labPatient := &domain.Patient{Name: "mno", NeedsLabTest: true}
+	visit(routed, labPatient)
+
+	// 8. Every step is recorded and can be exported as an audit trail
+	fmt.Println("\n--- Audit trail ---")
+	trail, err := json.MarshalIndent(labPatient.AuditTrail(), "", "  ")
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
+	}
+	fmt.Println(string(trail))
+
+	// 9. Invalid pipelines are rejected before any department is built
+	fmt.Println("\n--- Invalid pipelines ---")
+	for _, names := range [][]string{
+		{adapter.DeptReception, "radiology", adapter.DeptCashier},
+		{adapter.DeptReception, adapter.DeptDoctor, adapter.DeptReception},
+	} {
+		if _, err := builder.Build(names); err != nil {
+			fmt.Printf("error: %v\n", err)
+		}
+	}
+
+	// 10. Many patients at once: each department is a pool of workers with a queue
+	fmt.Println("\n--- Busy day simulation ---")
+	simulate()
+
+	// 11. A visit saved after every department resumes where it stopped
+	fmt.Println("\n--- Resumable visit ---")
+	resume(builder)
+}
+
+// resume stops a visit at the cashier and finishes it later from the saved
+// patient, without running the earlier departments again.
+func resume(builder *usecase.PipelineBuilder) {
+	repo := adapter.NewMemoryVisitRepository()
+	service, err := usecase.NewResumableVisitService(builder, adapter.DefaultPipeline, repo)
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
+	}
+
+	result, _ := service.Visit(&domain.Patient{Name: "pqr", PaymentDeclined: true})
+	fmt.Printf("Visit of %s stopped at %s: %s\n", result.Patient, result.StoppedBy, result.Reason)
+
+	// The patient comes back with another card
+	saved, err := repo.Load("pqr")
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
+	}
+	saved.PaymentDeclined = false
+	if err := repo.Save(saved); err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
+	}
+	if _, result, err = service.Resume("pqr"); err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
+	}
+	if result.Completed {
+		fmt.Printf("Visit of %s completed\n", result.Patient)
+	}
+}
+
+// simulate runs a crowd of patients through the default pipeline concurrently
+// and prints how each department coped.
+func simulate() {
+	sim, err := usecase.NewSimulation(adapter.NewDefaultPipelineBuilder(adapter.NopLogger{}), []usecase.StationConfig{
+		{Department: adapter.DeptReception, Workers: 1, QueueSize: 5, ServiceTime: 10 * time.Millisecond},
+		{Department: adapter.DeptDoctor, Workers: 3, QueueSize: 5, ServiceTime: 30 * time.Millisecond},
+		{Department: adapter.DeptCashier, Workers: 1, QueueSize: 5, ServiceTime: 5 * time.Millisecond},
+	})
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
+	}
+
+	patients := make([]*domain.Patient, 20)
+	for i := range patients {
+		patients[i] = &domain.Patient{Name: fmt.Sprintf("patient-%02d", i+1), PaymentDeclined: i%7 == 6}
+	}
+	report, err := sim.Run(context.Background(), patients)
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
+	}
+
+	fmt.Printf("%d completed, %d stopped in %v\n", report.Completed, report.Stopped, report.Elapsed.Round(time.Millisecond))
+	for _, st := range report.Stations {
+		fmt.Printf("%-10s workers=%d served=%d stopped=%d throughput=%.1f/s avg wait=%v max wait=%v utilization=%.0f%%\n",
+			st.Department, st.Workers, st.Served, st.Stopped, st.Throughput,
+			st.AvgWait.Round(time.Millisecond), st.MaxWait.Round(time.Millisecond), st.Utilization*100)
+	}
+}
+
+// visit sends the patient through the chain and prints how the visit ended.
+func visit(service *usecase.PatientVisitService, patient *domain.Patient) {
+	result, err := service.VisitHospital(patient)
+	switch {
+	case result.Completed:
+		fmt.Printf("Visit of %s completed\n", result.Patient)
+	case result.Halted:
+		fmt.Printf("Visit of %s stopped at %s: %s\n", result.Patient, result.StoppedBy, result.Reason)
+	default:
+		fmt.Printf("error: %v\n", err)
+	}
 }
@@ -1,6 +1,11 @@
 package usecase
 
-import "chain-of-responsibility-example/domain"
+import (
+	"errors"
+	"time"
+
+	"chain-of-responsibility-example/domain"
+)
 
 // PatientVisitService coordinates a visit through the department chain.
 type PatientVisitService struct {
@@ -15,8 +20,45 @@ func NewPatientVisitService(chainHead domain.Department) *PatientVisitService {
 }
 
 // VisitHospital starts the patient workflow at the chain head.
-func (s *PatientVisitService) VisitHospital(p *domain.Patient) {
-	if s.chainHead != nil {
-		s.chainHead.Execute(p)
+// If a department stops the visit, the result names it and gives the reason,
+// and the department's error is returned as well. The stop is also written
+// to the patient's record, so the audit trail shows where the visit ended.
+func (s *PatientVisitService) VisitHospital(p *domain.Patient) (domain.VisitResult, error) {
+	if s.chainHead == nil {
+		return domain.VisitResult{Patient: p.Name}, domain.ErrEmptyPipeline
+	}
+
+	err := s.chainHead.Execute(p)
+	return visitResult(p, err), err
+}
+
+// visitResult describes how the visit ended with err, and writes the stop
+// to the patient's record.
+func visitResult(p *domain.Patient, err error) domain.VisitResult {
+	result := domain.VisitResult{Patient: p.Name}
+	if err == nil {
+		result.Completed = true
+		return result
+	}
+
+	result.Reason = err.Error()
+	var deptErr *domain.DepartmentError
+	if errors.As(err, &deptErr) {
+		result.StoppedBy = deptErr.Department
+		result.Reason = deptErr.Err.Error()
+	}
+	var halt *domain.HaltError
+	if errors.As(err, &halt) {
+		result.Halted = true
+		result.Reason = halt.Reason
+	}
+	if result.StoppedBy != "" {
+		status := domain.StepFailed
+		if result.Halted {
+			status = domain.StepHalted
+		}
+		p.Record.Set(result.StoppedBy, status, time.Now())
+		p.Record.Note(result.StoppedBy, result.Reason)
 	}
+	return result
 }
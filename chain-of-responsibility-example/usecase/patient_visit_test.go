@@ -1,6 +1,7 @@
 package usecase_test
 
 import (
+	"errors"
 	"testing"
 
 	"chain-of-responsibility-example/domain"
@@ -10,14 +11,19 @@ import (
 // MockDepartment for testing the UseCase independently of adapters
 type MockDepartment struct {
 	Executed bool
+	Err      error
 	Next     domain.Department
 }
 
-func (m *MockDepartment) Execute(p *domain.Patient) {
+func (m *MockDepartment) Execute(p *domain.Patient) error {
 	m.Executed = true
+	if m.Err != nil {
+		return m.Err
+	}
 	if m.Next != nil {
-		m.Next.Execute(p)
+		return m.Next.Execute(p)
 	}
+	return nil
 }
 
 func (m *MockDepartment) SetNext(next domain.Department) {
@@ -31,10 +37,89 @@ func TestPatientVisitService_VisitHospital(t *testing.T) {
 	patient := &domain.Patient{Name: "Test Patient"}
 
 	// Act
-	service.VisitHospital(patient)
+	result, err := service.VisitHospital(patient)
 
 	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 	if !mockHead.Executed {
 		t.Error("Expected the chain head to be executed")
 	}
+	if !result.Completed || result.Patient != "Test Patient" {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestPatientVisitService_VisitHospitalStopped(t *testing.T) {
+	errBroken := errors.New("x-ray machine broken")
+
+	tests := []struct {
+		name    string
+		err     error
+		want    domain.VisitResult
+		wantErr error
+	}{
+		{
+			name:    "Halted By Department",
+			err:     &domain.DepartmentError{Department: "cashier", Err: domain.Halt("payment declined")},
+			want:    domain.VisitResult{Patient: "P", StoppedBy: "cashier", Reason: "payment declined", Halted: true},
+			wantErr: domain.ErrVisitHalted,
+		},
+		{
+			name:    "Failed In Department",
+			err:     &domain.DepartmentError{Department: "lab", Err: errBroken},
+			want:    domain.VisitResult{Patient: "P", StoppedBy: "lab", Reason: "x-ray machine broken"},
+			wantErr: errBroken,
+		},
+		{
+			name:    "Error Without Department",
+			err:     errBroken,
+			want:    domain.VisitResult{Patient: "P", Reason: "x-ray machine broken"},
+			wantErr: errBroken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			last := &MockDepartment{}
+			head := &MockDepartment{Err: tt.err, Next: last}
+
+			patient := &domain.Patient{Name: "P"}
+			result, err := usecase.NewPatientVisitService(head).VisitHospital(patient)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if result != tt.want {
+				t.Errorf("result = %+v, want %+v", result, tt.want)
+			}
+			if last.Executed {
+				t.Error("departments after the stop must not run")
+			}
+
+			// The stop is written to the record of the department that stopped the visit
+			steps := patient.Record.Steps()
+			if tt.want.StoppedBy == "" {
+				if len(steps) != 0 {
+					t.Errorf("unexpected record %+v", steps)
+				}
+				return
+			}
+			wantStatus := domain.StepFailed
+			if tt.want.Halted {
+				wantStatus = domain.StepHalted
+			}
+			if len(steps) != 1 || steps[0].Step != tt.want.StoppedBy || steps[0].Status != wantStatus ||
+				len(steps[0].Notes) != 1 || steps[0].Notes[0] != tt.want.Reason {
+				t.Errorf("record = %+v, want %s %s (%s)", steps, tt.want.StoppedBy, wantStatus, tt.want.Reason)
+			}
+		})
+	}
+}
+
+func TestPatientVisitService_NoChain(t *testing.T) {
+	_, err := usecase.NewPatientVisitService(nil).VisitHospital(&domain.Patient{Name: "P"})
+	if !errors.Is(err, domain.ErrEmptyPipeline) {
+		t.Errorf("error = %v, want ErrEmptyPipeline", err)
+	}
 }
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"chain-of-responsibility-example/domain"
+)
+
+// PipelineBuilder assembles department chains by name.
+// New departments (e.g. Pharmacy, Lab) are added by registering a factory;
+// the builder and the existing departments do not change.
+type PipelineBuilder struct {
+	factories map[string]domain.DepartmentFactory
+}
+
+// NewPipelineBuilder creates a builder with no departments registered.
+func NewPipelineBuilder() *PipelineBuilder {
+	return &PipelineBuilder{factories: make(map[string]domain.DepartmentFactory)}
+}
+
+// Register adds a department factory under name, replacing any previous one.
+func (b *PipelineBuilder) Register(name string, factory domain.DepartmentFactory) {
+	b.factories[name] = factory
+}
+
+// Names returns the registered department names in sorted order.
+func (b *PipelineBuilder) Names() []string {
+	names := make([]string, 0, len(b.factories))
+	for name := range b.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Build links the named departments in order and returns the head of the chain.
+// All names are validated before anything is built: an unknown name returns
+// domain.ErrUnknownDepartment, and a name listed twice returns
+// domain.ErrDepartmentCycle because the patient would be sent around again.
+func (b *PipelineBuilder) Build(names []string) (domain.Department, error) {
+	if err := b.validate(names); err != nil {
+		return nil, err
+	}
+
+	var head, tail domain.Department
+	for _, name := range names {
+		dept, err := b.New(name)
+		if err != nil {
+			return nil, err
+		}
+		if head == nil {
+			head = dept
+		} else {
+			tail.SetNext(dept)
+		}
+		tail = dept
+	}
+	return head, nil
+}
+
+// New builds a single department that is not linked to any other.
+func (b *PipelineBuilder) New(name string) (domain.Department, error) {
+	name = strings.TrimSpace(name)
+	factory, ok := b.factories[name]
+	if !ok {
+		return nil, b.unknown(name)
+	}
+	dept := factory()
+	if dept == nil {
+		return nil, fmt.Errorf("department %q: factory returned nil", name)
+	}
+	return dept, nil
+}
+
+// validate checks that names is a non-empty list of registered departments
+// without repeats.
+func (b *PipelineBuilder) validate(names []string) error {
+	if len(names) == 0 {
+		return domain.ErrEmptyPipeline
+	}
+	seen := make(map[string]int, len(names))
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		if _, ok := b.factories[name]; !ok {
+			return b.unknown(name)
+		}
+		if first, ok := seen[name]; ok {
+			return fmt.Errorf("%w: %q at positions %d and %d", domain.ErrDepartmentCycle, name, first+1, i+1)
+		}
+		seen[name] = i
+	}
+	return nil
+}
+
+func (b *PipelineBuilder) unknown(name string) error {
+	return fmt.Errorf("%w: %q (registered: %s)", domain.ErrUnknownDepartment, name, strings.Join(b.Names(), ", "))
+}
+
+// BuildFromConfig builds the chain listed in cfg.
+func (b *PipelineBuilder) BuildFromConfig(cfg domain.PipelineConfig) (domain.Department, error) {
+	return b.Build(cfg.Departments)
+}
@@ -0,0 +1,91 @@
+package usecase_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"chain-of-responsibility-example/domain"
+	"chain-of-responsibility-example/usecase"
+)
+
+// NamedDepartment records its name in a shared visit log
+type NamedDepartment struct {
+	Name    string
+	Visited *[]string
+	Next    domain.Department
+}
+
+func (d *NamedDepartment) Execute(p *domain.Patient) error {
+	*d.Visited = append(*d.Visited, d.Name)
+	if d.Next != nil {
+		return d.Next.Execute(p)
+	}
+	return nil
+}
+
+func (d *NamedDepartment) SetNext(next domain.Department) {
+	d.Next = next
+}
+
+func TestPipelineBuilder_Build(t *testing.T) {
+	var visited []string
+	builder := usecase.NewPipelineBuilder()
+	for _, name := range []string{"reception", "doctor", "lab", "pharmacy", "cashier"} {
+		builder.Register(name, func() domain.Department {
+			return &NamedDepartment{Name: name, Visited: &visited}
+		})
+	}
+
+	tests := []struct {
+		name    string
+		names   []string
+		want    []string
+		wantErr error
+	}{
+		{"Default Order", []string{"reception", "doctor", "cashier"}, []string{"reception", "doctor", "cashier"}, nil},
+		{"Lab And Pharmacy Inserted", []string{"reception", "doctor", "lab", "pharmacy", "cashier"}, []string{"reception", "doctor", "lab", "pharmacy", "cashier"}, nil},
+		{"Single Department", []string{"cashier"}, []string{"cashier"}, nil},
+		{"Names Are Trimmed", []string{" reception", "doctor "}, []string{"reception", "doctor"}, nil},
+		{"Empty", nil, nil, domain.ErrEmptyPipeline},
+		{"Unknown Name", []string{"reception", "radiology"}, nil, domain.ErrUnknownDepartment},
+		{"Repeated Name", []string{"reception", "doctor", "reception"}, nil, domain.ErrDepartmentCycle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			visited = nil
+			head, err := builder.Build(tt.names)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Build() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if head != nil {
+					t.Error("expected no chain on error")
+				}
+				return
+			}
+			if err := head.Execute(&domain.Patient{Name: "Test Patient"}); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(visited, tt.want) {
+				t.Errorf("visited %v, want %v", visited, tt.want)
+			}
+		})
+	}
+}
+
+func TestPipelineBuilder_BuildsFreshDepartments(t *testing.T) {
+	built := 0
+	builder := usecase.NewPipelineBuilder()
+	builder.Register("doctor", func() domain.Department {
+		built++
+		return &MockDepartment{}
+	})
+
+	first, _ := builder.BuildFromConfig(domain.PipelineConfig{Departments: []string{"doctor"}})
+	second, _ := builder.BuildFromConfig(domain.PipelineConfig{Departments: []string{"doctor"}})
+	if built != 2 || first == second {
+		t.Errorf("expected each chain to get its own departments, built %d", built)
+	}
+}
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"chain-of-responsibility-example/domain"
+)
+
+// ResumableVisitService runs visits that survive a crash.
+// The departments are not linked: the service runs them one after another
+// and saves the patient to the repository after each of them. A visit that
+// was interrupted is resumed at the first department whose step is not done.
+//
+// A department must record its step under the name it is registered with.
+// A step that was interrupted before the patient was saved runs again.
+type ResumableVisitService struct {
+	names       []string
+	departments []domain.Department
+	repo        domain.VisitRepository
+}
+
+// NewResumableVisitService builds the named departments from builder.
+// The names are validated like PipelineBuilder.Build.
+func NewResumableVisitService(builder *PipelineBuilder, names []string, repo domain.VisitRepository) (*ResumableVisitService, error) {
+	if err := builder.validate(names); err != nil {
+		return nil, err
+	}
+	s := &ResumableVisitService{repo: repo}
+	for _, name := range names {
+		dept, err := builder.New(name)
+		if err != nil {
+			return nil, err
+		}
+		s.names = append(s.names, strings.TrimSpace(name))
+		s.departments = append(s.departments, dept)
+	}
+	return s, nil
+}
+
+// Visit saves the patient and runs the departments whose steps are not done
+// yet, saving the patient after each one. A visit that stops is saved with
+// the stop recorded, so resuming it tries the stopping department again.
+func (s *ResumableVisitService) Visit(p *domain.Patient) (domain.VisitResult, error) {
+	if err := s.save(p); err != nil {
+		return domain.VisitResult{Patient: p.Name}, err
+	}
+	for i, dept := range s.departments {
+		if p.Record.Done(s.names[i]) {
+			continue
+		}
+		if err := dept.Execute(p); err != nil {
+			result := visitResult(p, err)
+			if saveErr := s.save(p); saveErr != nil {
+				return result, errors.Join(err, saveErr)
+			}
+			return result, err
+		}
+		if err := s.save(p); err != nil {
+			return domain.VisitResult{Patient: p.Name}, err
+		}
+	}
+	return visitResult(p, nil), nil
+}
+
+// Resume loads the saved visit of the named patient and continues it.
+// It returns domain.ErrVisitNotFound if no visit was saved.
+func (s *ResumableVisitService) Resume(name string) (*domain.Patient, domain.VisitResult, error) {
+	p, err := s.repo.Load(name)
+	if err != nil {
+		return nil, domain.VisitResult{Patient: name}, err
+	}
+	result, err := s.Visit(p)
+	return p, result, err
+}
+
+func (s *ResumableVisitService) save(p *domain.Patient) error {
+	if err := s.repo.Save(p); err != nil {
+		return fmt.Errorf("save visit of %s: %w", p.Name, err)
+	}
+	return nil
+}
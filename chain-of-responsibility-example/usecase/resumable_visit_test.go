@@ -0,0 +1,212 @@
+package usecase_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"chain-of-responsibility-example/domain"
+	"chain-of-responsibility-example/usecase"
+)
+
+// errCrash is the panic value of a simulated crash
+var errCrash = errors.New("crash")
+
+// MockVisitRepository keeps patients as JSON and can crash after a number of saves
+type MockVisitRepository struct {
+	Visits     map[string][]byte
+	Saves      int
+	CrashAfter int // Panics right after this save; 0 never crashes
+}
+
+func (m *MockVisitRepository) Save(p *domain.Patient) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if m.Visits == nil {
+		m.Visits = make(map[string][]byte)
+	}
+	m.Visits[p.Name] = data
+	m.Saves++
+	if m.Saves == m.CrashAfter {
+		panic(errCrash)
+	}
+	return nil
+}
+
+func (m *MockVisitRepository) Load(name string) (*domain.Patient, error) {
+	data, ok := m.Visits[name]
+	if !ok {
+		return nil, domain.ErrVisitNotFound
+	}
+	p := &domain.Patient{}
+	return p, json.Unmarshal(data, p)
+}
+
+// FakeHospital counts how often each department ran across service instances
+type FakeHospital struct {
+	Runs  map[string]int
+	Fail  map[string]error // Returned once by the department
+	Crash map[string]bool  // The department panics once, as if the process died mid-step
+}
+
+func (h *FakeHospital) Builder(names ...string) *usecase.PipelineBuilder {
+	builder := usecase.NewPipelineBuilder()
+	for _, name := range names {
+		builder.Register(name, func() domain.Department { return &FakeStep{Name: name, Hospital: h} })
+	}
+	return builder
+}
+
+// FakeStep records its step under its name
+type FakeStep struct {
+	Name     string
+	Hospital *FakeHospital
+}
+
+func (d *FakeStep) Execute(p *domain.Patient) error {
+	h := d.Hospital
+	h.Runs[d.Name]++
+	p.Record.Set(d.Name, domain.StepInProgress, time.Now())
+	if h.Crash[d.Name] {
+		delete(h.Crash, d.Name)
+		panic(errCrash)
+	}
+	if err := h.Fail[d.Name]; err != nil {
+		delete(h.Fail, d.Name)
+		return &domain.DepartmentError{Department: d.Name, Err: err}
+	}
+	p.Record.Set(d.Name, domain.StepDone, time.Now())
+	return nil
+}
+
+func (d *FakeStep) SetNext(domain.Department) {}
+
+// crashes runs fn and reports whether it panicked with errCrash
+func crashes(t *testing.T, fn func()) (crashed bool) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != nil {
+			if r != errCrash {
+				panic(r)
+			}
+			crashed = true
+		}
+	}()
+	fn()
+	return false
+}
+
+func TestResumableVisitService_ResumesAfterCrash(t *testing.T) {
+	names := []string{"reception", "doctor", "cashier"}
+
+	tests := []struct {
+		name       string
+		crashAfter int    // Saves before the crash: the first save is before any step
+		crashIn    string // Department that crashes while it works
+		wantRuns   map[string]int
+	}{
+		{"Before Any Step", 1, "", map[string]int{"reception": 1, "doctor": 1, "cashier": 1}},
+		{"After Reception", 2, "", map[string]int{"reception": 1, "doctor": 1, "cashier": 1}},
+		{"After Doctor", 3, "", map[string]int{"reception": 1, "doctor": 1, "cashier": 1}},
+		{"After Cashier", 4, "", map[string]int{"reception": 1, "doctor": 1, "cashier": 1}},
+		// An interrupted step was not saved as done, so it runs again
+		{"During Doctor", 0, "doctor", map[string]int{"reception": 1, "doctor": 2, "cashier": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hospital := &FakeHospital{Runs: map[string]int{}, Crash: map[string]bool{tt.crashIn: true}}
+			repo := &MockVisitRepository{CrashAfter: tt.crashAfter}
+			first, err := usecase.NewResumableVisitService(hospital.Builder(names...), names, repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !crashes(t, func() { first.Visit(&domain.Patient{Name: "abc"}) }) {
+				t.Fatal("the visit did not crash")
+			}
+
+			// A new service, as after a restart, continues from the saved patient
+			repo.CrashAfter = 0
+			second, err := usecase.NewResumableVisitService(hospital.Builder(names...), names, repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			patient, result, err := second.Resume("abc")
+			if err != nil || !result.Completed {
+				t.Fatalf("Resume() = %+v, %v", result, err)
+			}
+
+			for _, name := range names {
+				if hospital.Runs[name] != tt.wantRuns[name] {
+					t.Errorf("%s ran %d times, want %d", name, hospital.Runs[name], tt.wantRuns[name])
+				}
+			}
+			saved, _ := repo.Load("abc")
+			for _, p := range []*domain.Patient{patient, saved} {
+				var steps []string
+				for _, s := range p.Record.Steps() {
+					if s.Status != domain.StepDone {
+						t.Errorf("step %s is %s", s.Step, s.Status)
+					}
+					steps = append(steps, s.Step)
+				}
+				if !slices.Equal(steps, names) {
+					t.Errorf("steps = %v, want %v", steps, names)
+				}
+			}
+		})
+	}
+}
+
+func TestResumableVisitService_ResumesHaltedVisit(t *testing.T) {
+	names := []string{"reception", "doctor", "cashier"}
+	hospital := &FakeHospital{Runs: map[string]int{}, Fail: map[string]error{"cashier": domain.Halt("payment declined")}}
+	repo := &MockVisitRepository{}
+	service, err := usecase.NewResumableVisitService(hospital.Builder(names...), names, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := service.Visit(&domain.Patient{Name: "abc"})
+	if !errors.Is(err, domain.ErrVisitHalted) || result.StoppedBy != "cashier" || !result.Halted {
+		t.Fatalf("Visit() = %+v, %v", result, err)
+	}
+	saved, err := repo.Load("abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec, _ := saved.Record.Get("cashier"); rec.Status != domain.StepHalted {
+		t.Errorf("saved cashier step = %+v, want halted", rec)
+	}
+
+	// Once the patient can pay, only the cashier runs again
+	_, result, err = service.Resume("abc")
+	if err != nil || !result.Completed {
+		t.Fatalf("Resume() = %+v, %v", result, err)
+	}
+	want := map[string]int{"reception": 1, "doctor": 1, "cashier": 2}
+	if fmt.Sprint(hospital.Runs) != fmt.Sprint(want) {
+		t.Errorf("runs = %v, want %v", hospital.Runs, want)
+	}
+}
+
+func TestResumableVisitService_Errors(t *testing.T) {
+	hospital := &FakeHospital{Runs: map[string]int{}}
+
+	if _, err := usecase.NewResumableVisitService(hospital.Builder("doctor"), []string{"doctor", "radiology"}, &MockVisitRepository{}); !errors.Is(err, domain.ErrUnknownDepartment) {
+		t.Errorf("NewResumableVisitService() error = %v, want ErrUnknownDepartment", err)
+	}
+
+	service, err := usecase.NewResumableVisitService(hospital.Builder("doctor"), []string{"doctor"}, &MockVisitRepository{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := service.Resume("nobody"); !errors.Is(err, domain.ErrVisitNotFound) {
+		t.Errorf("Resume() error = %v, want ErrVisitNotFound", err)
+	}
+}
@@ -0,0 +1,260 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"chain-of-responsibility-example/domain"
+)
+
+// StationConfig describes one department in a simulation.
+type StationConfig struct {
+	Department  string        // Registered department name
+	Workers     int           // Patients served at the same time
+	QueueSize   int           // Patients that can wait; a full queue holds up the previous department
+	ServiceTime time.Duration // Time spent with each patient
+}
+
+// StationReport summarizes one department after a simulation.
+type StationReport struct {
+	Department  string
+	Workers     int
+	Served      int
+	Stopped     int           // Visits that ended here because the department returned an error
+	Throughput  float64       // Patients served per second
+	AvgWait     time.Duration // Time spent in the queue
+	MaxWait     time.Duration
+	Utilization float64 // Share of the workers' time spent serving patients (0-1)
+}
+
+// SimulationReport summarizes a simulation run.
+type SimulationReport struct {
+	Elapsed   time.Duration
+	Completed int
+	Stopped   int
+	Cancelled int // Patients still in the hospital when the context was cancelled
+	Stations  []StationReport
+	Results   []domain.VisitResult // Finished visits, in the order they finished
+}
+
+// Simulation moves many patients through the departments concurrently.
+// Each department is a pool of workers fed by a bounded queue; patients are
+// handed from one department's queue to the next over channels.
+type Simulation struct {
+	stations []*station
+}
+
+type station struct {
+	StationConfig
+	departments []domain.Department // One per worker, unlinked
+	queue       chan visitJob
+
+	mu      sync.Mutex
+	served  int
+	stopped int
+	waited  time.Duration
+	maxWait time.Duration
+	busy    time.Duration
+}
+
+type visitJob struct {
+	patient  *domain.Patient
+	enqueued time.Time
+}
+
+// NewSimulation builds the stations in order. Departments come from builder
+// and each worker gets its own instance.
+func NewSimulation(builder *PipelineBuilder, configs []StationConfig) (*Simulation, error) {
+	if len(configs) == 0 {
+		return nil, domain.ErrEmptyPipeline
+	}
+	seen := make(map[string]bool, len(configs))
+	sim := &Simulation{}
+	for _, cfg := range configs {
+		if seen[cfg.Department] {
+			return nil, fmt.Errorf("%w: %q", domain.ErrDepartmentCycle, cfg.Department)
+		}
+		seen[cfg.Department] = true
+		if cfg.Workers <= 0 || cfg.QueueSize < 0 || cfg.ServiceTime < 0 {
+			return nil, fmt.Errorf("station %q: workers must be positive, queue size and service time not negative", cfg.Department)
+		}
+		st := &station{StationConfig: cfg}
+		for range cfg.Workers {
+			dept, err := builder.New(cfg.Department)
+			if err != nil {
+				return nil, err
+			}
+			st.departments = append(st.departments, dept)
+		}
+		sim.stations = append(sim.stations, st)
+	}
+	return sim, nil
+}
+
+// Run admits the patients in order and waits until every visit has finished.
+// Cancelling ctx stops the simulation: patients still waiting or being served
+// are counted as cancelled and ctx.Err() is returned with the report.
+// Run returns only after every worker has stopped; a department that is
+// serving a patient when ctx is cancelled is allowed to finish first.
+// A Simulation can be run once.
+func (s *Simulation) Run(ctx context.Context, patients []*domain.Patient) (SimulationReport, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for _, st := range s.stations {
+		st.queue = make(chan visitJob, st.QueueSize)
+	}
+	results := make(chan domain.VisitResult)
+	start := time.Now()
+
+	// Every goroutine of the run is in all; results is closed only when all
+	// of them have returned, so no worker can send on a closed channel and
+	// none is left running after Run returns
+	var all sync.WaitGroup
+
+	// Each station closes the next queue once its workers are done,
+	// so patients drain through the hospital in order
+	for i, st := range s.stations {
+		var next chan visitJob
+		if i+1 < len(s.stations) {
+			next = s.stations[i+1].queue
+		}
+		var station sync.WaitGroup
+		for _, dept := range st.departments {
+			station.Add(1)
+			all.Go(func() {
+				defer station.Done()
+				st.work(ctx, dept, next, results)
+			})
+		}
+		if next != nil {
+			all.Go(func() {
+				station.Wait()
+				close(next)
+			})
+		}
+	}
+
+	all.Go(func() {
+		defer close(s.stations[0].queue)
+		for _, p := range patients {
+			select {
+			case s.stations[0].queue <- visitJob{patient: p, enqueued: time.Now()}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+	go func() {
+		all.Wait()
+		close(results)
+	}()
+
+	report := SimulationReport{}
+	for result := range results {
+		report.Results = append(report.Results, result)
+		if result.Completed {
+			report.Completed++
+		} else {
+			report.Stopped++
+		}
+	}
+	report.Elapsed = time.Since(start)
+	report.Cancelled = len(patients) - report.Completed - report.Stopped
+	for _, st := range s.stations {
+		report.Stations = append(report.Stations, st.report(report.Elapsed))
+	}
+	return report, ctx.Err()
+}
+
+// work serves patients from the queue until it is closed or ctx is done.
+// next is nil for the last station, whose patients have finished their visit.
+func (st *station) work(ctx context.Context, dept domain.Department, next chan<- visitJob, results chan<- domain.VisitResult) {
+	for {
+		var job visitJob
+		var ok bool
+		select {
+		case job, ok = <-st.queue:
+			if !ok {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+		wait := time.Since(job.enqueued)
+
+		began := time.Now()
+		if !sleep(ctx, st.ServiceTime) {
+			return
+		}
+		err := dept.Execute(job.patient)
+		st.record(wait, time.Since(began), err != nil)
+
+		var out visitJob
+		var dest chan<- visitJob
+		var result domain.VisitResult
+		var done chan<- domain.VisitResult
+		switch {
+		case err != nil || next == nil:
+			result, done = visitResult(job.patient, err), results
+		default:
+			out, dest = visitJob{patient: job.patient, enqueued: time.Now()}, next
+		}
+		// A full queue downstream holds this worker up, like a crowded corridor
+		select {
+		case dest <- out:
+		case done <- result:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (st *station) record(wait, busy time.Duration, stopped bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.served++
+	if stopped {
+		st.stopped++
+	}
+	st.waited += wait
+	st.maxWait = max(st.maxWait, wait)
+	st.busy += busy
+}
+
+func (st *station) report(elapsed time.Duration) StationReport {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	r := StationReport{
+		Department: st.Department,
+		Workers:    st.Workers,
+		Served:     st.served,
+		Stopped:    st.stopped,
+		MaxWait:    st.maxWait,
+	}
+	if st.served > 0 {
+		r.AvgWait = st.waited / time.Duration(st.served)
+	}
+	if elapsed > 0 {
+		r.Throughput = float64(st.served) / elapsed.Seconds()
+		r.Utilization = min(1, float64(st.busy)/(float64(elapsed)*float64(st.Workers)))
+	}
+	return r
+}
+
+// sleep waits for d and reports false if ctx was cancelled first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
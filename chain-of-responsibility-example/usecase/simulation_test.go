@@ -0,0 +1,243 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"chain-of-responsibility-example/domain"
+	"chain-of-responsibility-example/usecase"
+)
+
+// SimDepartment is a concurrency-safe department that records its step and
+// tracks how many patients it serves at once
+type SimDepartment struct {
+	Name string
+	Halt func(p *domain.Patient) bool
+
+	mu          sync.Mutex
+	inFlight    int
+	MaxInFlight int
+}
+
+func (d *SimDepartment) Execute(p *domain.Patient) error {
+	d.mu.Lock()
+	d.inFlight++
+	d.MaxInFlight = max(d.MaxInFlight, d.inFlight)
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		d.inFlight--
+		d.mu.Unlock()
+	}()
+
+	time.Sleep(time.Millisecond)
+	if d.Halt != nil && d.Halt(p) {
+		return &domain.DepartmentError{Department: d.Name, Err: domain.Halt("payment declined")}
+	}
+	p.Record.Set(d.Name, domain.StepDone, time.Now())
+	return nil
+}
+
+func (d *SimDepartment) SetNext(domain.Department) {}
+
+func newSimBuilder(depts ...*SimDepartment) *usecase.PipelineBuilder {
+	builder := usecase.NewPipelineBuilder()
+	for _, d := range depts {
+		// Workers share the instance so the test can see the concurrency
+		builder.Register(d.Name, func() domain.Department { return d })
+	}
+	return builder
+}
+
+func newPatients(n int, declined func(i int) bool) []*domain.Patient {
+	patients := make([]*domain.Patient, n)
+	for i := range patients {
+		patients[i] = &domain.Patient{Name: fmt.Sprintf("patient-%02d", i), PaymentDeclined: declined(i)}
+	}
+	return patients
+}
+
+func TestSimulation_Run(t *testing.T) {
+	reception := &SimDepartment{Name: "reception"}
+	doctor := &SimDepartment{Name: "doctor"}
+	cashier := &SimDepartment{Name: "cashier", Halt: func(p *domain.Patient) bool { return p.PaymentDeclined }}
+	sim, err := usecase.NewSimulation(newSimBuilder(reception, doctor, cashier), []usecase.StationConfig{
+		{Department: "reception", Workers: 2, QueueSize: 4, ServiceTime: 2 * time.Millisecond},
+		{Department: "doctor", Workers: 3, QueueSize: 2, ServiceTime: 5 * time.Millisecond},
+		{Department: "cashier", Workers: 1, QueueSize: 1, ServiceTime: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patients := newPatients(24, func(i int) bool { return i%6 == 0 })
+	report, err := sim.Run(context.Background(), patients)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if report.Completed != 20 || report.Stopped != 4 || report.Cancelled != 0 || len(report.Results) != 24 {
+		t.Fatalf("completed %d, stopped %d, cancelled %d, results %d", report.Completed, report.Stopped, report.Cancelled, len(report.Results))
+	}
+	for _, r := range report.Results {
+		if !r.Completed && (r.StoppedBy != "cashier" || !r.Halted || r.Reason != "payment declined") {
+			t.Errorf("unexpected result %+v", r)
+		}
+	}
+
+	wantWorkers := map[string]int{"reception": 2, "doctor": 3, "cashier": 1}
+	for i, st := range report.Stations {
+		if st.Department != []string{"reception", "doctor", "cashier"}[i] || st.Served != 24 {
+			t.Errorf("station %d: %+v", i, st)
+		}
+		if st.Throughput <= 0 || st.Utilization <= 0 || st.Utilization > 1 || st.AvgWait > st.MaxWait {
+			t.Errorf("%s: implausible stats %+v", st.Department, st)
+		}
+		if st.Workers != wantWorkers[st.Department] {
+			t.Errorf("%s: workers = %d", st.Department, st.Workers)
+		}
+	}
+	if report.Stations[2].Stopped != 4 {
+		t.Errorf("cashier stopped %d visits, want 4", report.Stations[2].Stopped)
+	}
+	// The single cashier is the bottleneck, so patients queue up in front of it
+	if report.Stations[2].MaxWait == 0 {
+		t.Error("expected patients to wait for the cashier")
+	}
+
+	for name, d := range map[string]*SimDepartment{"reception": reception, "doctor": doctor, "cashier": cashier} {
+		if d.MaxInFlight > wantWorkers[name] {
+			t.Errorf("%s served %d patients at once with %d workers", name, d.MaxInFlight, wantWorkers[name])
+		}
+	}
+	if doctor.MaxInFlight < 2 {
+		t.Errorf("doctors never worked in parallel (max %d)", doctor.MaxInFlight)
+	}
+
+	// Each patient went through the departments in order
+	for _, p := range patients {
+		var steps []string
+		for _, s := range p.Record.Steps() {
+			steps = append(steps, s.Step)
+		}
+		if !slices.Equal(steps, []string{"reception", "doctor", "cashier"}) {
+			t.Errorf("%s: steps %v", p.Name, steps)
+		}
+	}
+}
+
+func TestSimulation_Cancel(t *testing.T) {
+	sim, err := usecase.NewSimulation(newSimBuilder(&SimDepartment{Name: "doctor"}), []usecase.StationConfig{
+		{Department: "doctor", Workers: 2, QueueSize: 2, ServiceTime: time.Hour},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	began := time.Now()
+	report, err := sim.Run(ctx, newPatients(10, func(int) bool { return false }))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run() error = %v, want DeadlineExceeded", err)
+	}
+	if report.Cancelled != 10 || report.Completed != 0 {
+		t.Errorf("cancelled %d, completed %d, want 10 and 0", report.Cancelled, report.Completed)
+	}
+	if elapsed := time.Since(began); elapsed > time.Second {
+		t.Errorf("Run() took %v after cancellation", elapsed)
+	}
+}
+
+// StallDepartment takes a while with every patient, then halts declined
+// payments and fails lab patients
+type StallDepartment struct {
+	Name  string
+	Delay time.Duration
+}
+
+func (d *StallDepartment) Execute(p *domain.Patient) error {
+	time.Sleep(d.Delay)
+	switch {
+	case p.PaymentDeclined:
+		return &domain.DepartmentError{Department: d.Name, Err: domain.Halt("payment declined")}
+	case p.NeedsLabTest:
+		return &domain.DepartmentError{Department: d.Name, Err: errors.New("lab closed")}
+	}
+	return nil
+}
+
+func (d *StallDepartment) SetNext(domain.Department) {}
+
+func TestSimulation_CancelWhileStopping(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	for round := range 20 {
+		builder := usecase.NewPipelineBuilder()
+		builder.Register("doctor", func() domain.Department { return &StallDepartment{Name: "doctor", Delay: time.Millisecond} })
+		builder.Register("cashier", func() domain.Department { return &StallDepartment{Name: "cashier", Delay: 2 * time.Millisecond} })
+		sim, err := usecase.NewSimulation(builder, []usecase.StationConfig{
+			{Department: "doctor", Workers: 4},
+			{Department: "cashier", Workers: 1},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		patients := make([]*domain.Patient, 40)
+		for i := range patients {
+			patients[i] = &domain.Patient{Name: fmt.Sprintf("patient-%02d", i), PaymentDeclined: i%3 == 0, NeedsLabTest: i%3 == 1}
+		}
+		// Cancel while workers of both stations are busy or blocked on a send
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(round%5+1)*time.Millisecond)
+		report, err := sim.Run(ctx, patients)
+		cancel()
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("round %d: Run() error = %v, want DeadlineExceeded", round, err)
+		}
+		if report.Completed+report.Stopped+report.Cancelled != len(patients) || report.Cancelled == 0 {
+			t.Fatalf("round %d: completed %d, stopped %d, cancelled %d", round, report.Completed, report.Stopped, report.Cancelled)
+		}
+	}
+
+	// Run waits for its workers, so none are left behind
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > baseline {
+		t.Errorf("%d goroutines left running, want %d", n, baseline)
+	}
+}
+
+func TestNewSimulation_Invalid(t *testing.T) {
+	builder := newSimBuilder(&SimDepartment{Name: "doctor"}, &SimDepartment{Name: "cashier"})
+
+	tests := []struct {
+		name    string
+		configs []usecase.StationConfig
+		wantErr error
+	}{
+		{"Empty", nil, domain.ErrEmptyPipeline},
+		{"Unknown Department", []usecase.StationConfig{{Department: "radiology", Workers: 1}}, domain.ErrUnknownDepartment},
+		{"Repeated Department", []usecase.StationConfig{{Department: "doctor", Workers: 1}, {Department: "doctor", Workers: 1}}, domain.ErrDepartmentCycle},
+		{"No Workers", []usecase.StationConfig{{Department: "cashier"}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := usecase.NewSimulation(builder, tt.configs)
+			if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("NewSimulation() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,19 +3,67 @@ package adapter
 import (
 	"command-example/domain"
 	"fmt"
+	"unicode/utf8"
 )
 
 // Ensure implementation
 var (
 	_ domain.Command = (*InsertCommand)(nil)
 	_ domain.Command = (*DeleteCommand)(nil)
+	_ domain.Command = (*InsertAtCommand)(nil)
+	_ domain.Command = (*DeleteRangeCommand)(nil)
+	_ domain.Command = (*MoveCursorCommand)(nil)
+	_ domain.Command = (*SelectCommand)(nil)
+	_ domain.Command = (*ReplaceSelectionCommand)(nil)
 )
 
+// Commands use value receivers: Do works on a copy of the command, records the
+// undo state on that copy, and returns it. The original is never modified.
+
+// caret remembers the cursor and anchor so Undo can restore them exactly.
+type caret struct {
+	cursor int
+	anchor int
+}
+
+func saveCaret(b *domain.Buffer) caret {
+	return caret{cursor: b.Cursor, anchor: b.Anchor}
+}
+
+func (c caret) restore(b *domain.Buffer) error {
+	if n := b.Len(); c.cursor > n || c.anchor > n {
+		return fmt.Errorf("%w: cursor %d/anchor %d beyond length %d", domain.ErrBufferMismatch, c.cursor, c.anchor, n)
+	}
+	b.Cursor = c.cursor
+	b.Anchor = c.anchor
+	return nil
+}
+
+// expectText checks that the buffer holds text at pos before it is removed.
+func expectText(b *domain.Buffer, pos int, text string) error {
+	end := pos + utf8.RuneCountInString(text)
+	if end > b.Len() || b.Text(pos, end) != text {
+		return fmt.Errorf("%w: expected '%s' at %d", domain.ErrBufferMismatch, text, pos)
+	}
+	return nil
+}
+
+// expectContent checks that the buffer still holds what a deletion left in it,
+// so the deleted text goes back between the same neighbours.
+func expectContent(b *domain.Buffer, content string) error {
+	if b.Content != content {
+		return fmt.Errorf("%w: buffer changed since the deletion", domain.ErrBufferMismatch)
+	}
+	return nil
+}
+
 // --- 1. Insert Command ---
 
 // InsertCommand appends text to the buffer.
 type InsertCommand struct {
 	textToInsert string
+	insertedAt   int   // State to save for Undo
+	before       caret // State to save for Undo
 	logger       domain.Logger
 }
 
@@ -27,18 +75,23 @@ func NewInsertCommand(text string, logger domain.Logger) *InsertCommand {
 	}
 }
 
-func (c *InsertCommand) Do(b *domain.Buffer) {
-	b.Content += c.textToInsert
+func (c InsertCommand) Do(b *domain.Buffer) (domain.Command, error) {
+	c.before = saveCaret(b)
+	c.insertedAt = b.InsertAt(b.Len(), c.textToInsert)
 	c.logger.Log(fmt.Sprintf("[CMD] Inserted: '%s'", c.textToInsert))
+	return c, nil
 }
 
-func (c *InsertCommand) Undo(b *domain.Buffer) {
-	length := len(c.textToInsert)
-	currentLen := len(b.Content)
-	if currentLen >= length {
-		b.Content = b.Content[:currentLen-length]
-		c.logger.Log(fmt.Sprintf("[CMD] Undid Insert: Removed '%s'", c.textToInsert))
+func (c InsertCommand) Undo(b *domain.Buffer) error {
+	if err := expectText(b, c.insertedAt, c.textToInsert); err != nil {
+		return fmt.Errorf("undo insert: %w", err)
 	}
+	b.DeleteRange(c.insertedAt, c.insertedAt+utf8.RuneCountInString(c.textToInsert))
+	if err := c.before.restore(b); err != nil {
+		return fmt.Errorf("undo insert: %w", err)
+	}
+	c.logger.Log(fmt.Sprintf("[CMD] Undid Insert: Removed '%s'", c.textToInsert))
+	return nil
 }
 
 // --- 2. Delete Command (Stateful) ---
@@ -47,7 +100,10 @@ func (c *InsertCommand) Undo(b *domain.Buffer) {
 // It remembers what was deleted to support Undo.
 type DeleteCommand struct {
 	count       int
+	deletedAt   int    // State to save for Undo
 	deletedText string // State to save for Undo
+	after       string // State to save for Undo (the content left by Do)
+	before      caret  // State to save for Undo
 	logger      domain.Logger
 }
 
@@ -59,23 +115,226 @@ func NewDeleteCommand(count int, logger domain.Logger) *DeleteCommand {
 	}
 }
 
-func (c *DeleteCommand) Do(b *domain.Buffer) {
-	currentLen := len(b.Content)
-	if currentLen < c.count {
-		c.count = currentLen // Adjust to available length
+func (c DeleteCommand) Do(b *domain.Buffer) (domain.Command, error) {
+	if c.count < 0 {
+		return nil, fmt.Errorf("delete: %w: negative count %d", domain.ErrInvalidArgument, c.count)
 	}
 
+	// Adjust to available length
+	currentLen := b.Len()
+	count := min(c.count, currentLen)
+
 	// Save state for Undo
-	start := currentLen - c.count
-	c.deletedText = b.Content[start:]
+	c.before = saveCaret(b)
 
 	// Execute
-	b.Content = b.Content[:start]
-	c.logger.Log(fmt.Sprintf("[CMD] Deleted last %d chars: '%s'", c.count, c.deletedText))
+	c.deletedAt = currentLen - count
+	c.deletedText = b.DeleteRange(c.deletedAt, currentLen)
+	c.after = b.Content
+	c.logger.Log(fmt.Sprintf("[CMD] Deleted last %d chars: '%s'", count, c.deletedText))
+	return c, nil
 }
 
-func (c *DeleteCommand) Undo(b *domain.Buffer) {
+func (c DeleteCommand) Undo(b *domain.Buffer) error {
+	if err := expectContent(b, c.after); err != nil {
+		return fmt.Errorf("undo delete: %w", err)
+	}
 	// Restore state
-	b.Content += c.deletedText
+	b.InsertAt(c.deletedAt, c.deletedText)
+	if err := c.before.restore(b); err != nil {
+		return fmt.Errorf("undo delete: %w", err)
+	}
 	c.logger.Log(fmt.Sprintf("[CMD] Undid Delete: Restored '%s'", c.deletedText))
+	return nil
+}
+
+// --- 3. InsertAt Command ---
+
+// InsertAtCommand inserts text at a rune offset.
+type InsertAtCommand struct {
+	pos          int
+	textToInsert string
+	insertedAt   int   // State to save for Undo (pos after clamping)
+	before       caret // State to save for Undo
+	logger       domain.Logger
+}
+
+// NewInsertAtCommand builds an InsertAtCommand.
+func NewInsertAtCommand(pos int, text string, logger domain.Logger) *InsertAtCommand {
+	return &InsertAtCommand{
+		pos:          pos,
+		textToInsert: text,
+		logger:       logger,
+	}
+}
+
+func (c InsertAtCommand) Do(b *domain.Buffer) (domain.Command, error) {
+	c.before = saveCaret(b)
+	c.insertedAt = b.InsertAt(c.pos, c.textToInsert)
+	c.logger.Log(fmt.Sprintf("[CMD] Inserted at %d: '%s'", c.insertedAt, c.textToInsert))
+	return c, nil
+}
+
+func (c InsertAtCommand) Undo(b *domain.Buffer) error {
+	if err := expectText(b, c.insertedAt, c.textToInsert); err != nil {
+		return fmt.Errorf("undo insert at: %w", err)
+	}
+	b.DeleteRange(c.insertedAt, c.insertedAt+utf8.RuneCountInString(c.textToInsert))
+	if err := c.before.restore(b); err != nil {
+		return fmt.Errorf("undo insert at: %w", err)
+	}
+	c.logger.Log(fmt.Sprintf("[CMD] Undid InsertAt: Removed '%s'", c.textToInsert))
+	return nil
+}
+
+// --- 4. DeleteRange Command (Stateful) ---
+
+// DeleteRangeCommand removes the runes between start and end.
+type DeleteRangeCommand struct {
+	start       int
+	end         int
+	deletedAt   int    // State to save for Undo
+	deletedText string // State to save for Undo
+	after       string // State to save for Undo (the content left by Do)
+	before      caret  // State to save for Undo
+	logger      domain.Logger
+}
+
+// NewDeleteRangeCommand builds a DeleteRangeCommand.
+func NewDeleteRangeCommand(start, end int, logger domain.Logger) *DeleteRangeCommand {
+	return &DeleteRangeCommand{
+		start:  start,
+		end:    end,
+		logger: logger,
+	}
+}
+
+func (c DeleteRangeCommand) Do(b *domain.Buffer) (domain.Command, error) {
+	c.before = saveCaret(b)
+	c.deletedAt = b.Clamp(min(c.start, c.end))
+	c.deletedText = b.DeleteRange(c.start, c.end)
+	c.after = b.Content
+	c.logger.Log(fmt.Sprintf("[CMD] Deleted range [%d,%d): '%s'", c.start, c.end, c.deletedText))
+	return c, nil
+}
+
+func (c DeleteRangeCommand) Undo(b *domain.Buffer) error {
+	if err := expectContent(b, c.after); err != nil {
+		return fmt.Errorf("undo delete range: %w", err)
+	}
+	b.InsertAt(c.deletedAt, c.deletedText)
+	if err := c.before.restore(b); err != nil {
+		return fmt.Errorf("undo delete range: %w", err)
+	}
+	c.logger.Log(fmt.Sprintf("[CMD] Undid DeleteRange: Restored '%s'", c.deletedText))
+	return nil
+}
+
+// --- 5. Cursor Commands ---
+
+// MoveCursorCommand moves the cursor and clears the selection.
+type MoveCursorCommand struct {
+	pos    int
+	before caret // State to save for Undo
+	logger domain.Logger
+}
+
+// NewMoveCursorCommand builds a MoveCursorCommand.
+func NewMoveCursorCommand(pos int, logger domain.Logger) *MoveCursorCommand {
+	return &MoveCursorCommand{
+		pos:    pos,
+		logger: logger,
+	}
+}
+
+func (c MoveCursorCommand) Do(b *domain.Buffer) (domain.Command, error) {
+	c.before = saveCaret(b)
+	b.MoveCursor(c.pos)
+	c.logger.Log(fmt.Sprintf("[CMD] Moved cursor to %d", b.Cursor))
+	return c, nil
+}
+
+func (c MoveCursorCommand) Undo(b *domain.Buffer) error {
+	if err := c.before.restore(b); err != nil {
+		return fmt.Errorf("undo move cursor: %w", err)
+	}
+	c.logger.Log(fmt.Sprintf("[CMD] Undid MoveCursor: Back to %d", b.Cursor))
+	return nil
+}
+
+// SelectCommand selects a range of runes.
+type SelectCommand struct {
+	start  int
+	end    int
+	before caret // State to save for Undo
+	logger domain.Logger
+}
+
+// NewSelectCommand builds a SelectCommand.
+func NewSelectCommand(start, end int, logger domain.Logger) *SelectCommand {
+	return &SelectCommand{
+		start:  start,
+		end:    end,
+		logger: logger,
+	}
+}
+
+func (c SelectCommand) Do(b *domain.Buffer) (domain.Command, error) {
+	c.before = saveCaret(b)
+	b.Select(c.start, c.end)
+	start, end := b.Selection()
+	c.logger.Log(fmt.Sprintf("[CMD] Selected [%d,%d): '%s'", start, end, b.Text(start, end)))
+	return c, nil
+}
+
+func (c SelectCommand) Undo(b *domain.Buffer) error {
+	if err := c.before.restore(b); err != nil {
+		return fmt.Errorf("undo select: %w", err)
+	}
+	c.logger.Log("[CMD] Undid Select")
+	return nil
+}
+
+// --- 6. ReplaceSelection Command (Stateful) ---
+
+// ReplaceSelectionCommand replaces the selected text (or inserts at the cursor
+// when nothing is selected) and leaves the cursor after the new text.
+type ReplaceSelectionCommand struct {
+	replacement string
+	replacedAt  int    // State to save for Undo
+	replaced    string // State to save for Undo
+	before      caret  // State to save for Undo
+	logger      domain.Logger
+}
+
+// NewReplaceSelectionCommand builds a ReplaceSelectionCommand.
+func NewReplaceSelectionCommand(text string, logger domain.Logger) *ReplaceSelectionCommand {
+	return &ReplaceSelectionCommand{
+		replacement: text,
+		logger:      logger,
+	}
+}
+
+func (c ReplaceSelectionCommand) Do(b *domain.Buffer) (domain.Command, error) {
+	c.before = saveCaret(b)
+	start, end := b.Selection()
+	c.replacedAt = start
+	c.replaced = b.DeleteRange(start, end)
+	b.InsertAt(start, c.replacement)
+	b.MoveCursor(start + utf8.RuneCountInString(c.replacement))
+	c.logger.Log(fmt.Sprintf("[CMD] Replaced '%s' with '%s'", c.replaced, c.replacement))
+	return c, nil
+}
+
+func (c ReplaceSelectionCommand) Undo(b *domain.Buffer) error {
+	if err := expectText(b, c.replacedAt, c.replacement); err != nil {
+		return fmt.Errorf("undo replace: %w", err)
+	}
+	b.DeleteRange(c.replacedAt, c.replacedAt+utf8.RuneCountInString(c.replacement))
+	b.InsertAt(c.replacedAt, c.replaced)
+	if err := c.before.restore(b); err != nil {
+		return fmt.Errorf("undo replace: %w", err)
+	}
+	c.logger.Log(fmt.Sprintf("[CMD] Undid Replace: Restored '%s'", c.replaced))
+	return nil
 }
@@ -1,6 +1,7 @@
 package adapter_test
 
 import (
+	"errors"
 	"testing"
 
 	"command-example/adapter"
@@ -15,17 +16,33 @@ func (m *MockLogger) Log(message string) {
 	m.Logs = append(m.Logs, message)
 }
 
+func mustDo(t *testing.T, c domain.Command, b *domain.Buffer) domain.Command {
+	t.Helper()
+	done, err := c.Do(b)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	return done
+}
+
+func mustUndo(t *testing.T, c domain.Command, b *domain.Buffer) {
+	t.Helper()
+	if err := c.Undo(b); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+}
+
 func TestInsertCommand(t *testing.T) {
 	logger := &MockLogger{}
 	buffer := domain.NewBuffer()
 	cmd := adapter.NewInsertCommand("Hello", logger)
 
-	cmd.Do(buffer)
+	done := mustDo(t, cmd, buffer)
 	if buffer.Content != "Hello" {
 		t.Errorf("Insert failed: %s", buffer.Content)
 	}
 
-	cmd.Undo(buffer)
+	mustUndo(t, done, buffer)
 	if buffer.Content != "" {
 		t.Errorf("Undo Insert failed: %s", buffer.Content)
 	}
@@ -39,13 +56,248 @@ func TestDeleteCommand(t *testing.T) {
 	// Delete "World" (5 chars)
 	cmd := adapter.NewDeleteCommand(5, logger)
 
-	cmd.Do(buffer)
+	done := mustDo(t, cmd, buffer)
 	if buffer.Content != "Hello" {
 		t.Errorf("Delete failed: %s", buffer.Content)
 	}
 
-	cmd.Undo(buffer)
+	mustUndo(t, done, buffer)
 	if buffer.Content != "HelloWorld" {
 		t.Errorf("Undo Delete failed: %s", buffer.Content)
 	}
 }
+
+func TestInsertAndDeleteCommand_MultiByte(t *testing.T) {
+	logger := &MockLogger{}
+	buffer := domain.NewBuffer()
+	buffer.Content = "こんにちは"
+
+	insert := adapter.NewInsertCommand("世界", logger)
+	doneInsert := mustDo(t, insert, buffer)
+	if buffer.Content != "こんにちは世界" {
+		t.Errorf("Insert failed: %s", buffer.Content)
+	}
+	mustUndo(t, doneInsert, buffer)
+	if buffer.Content != "こんにちは" {
+		t.Errorf("Undo Insert failed: %s", buffer.Content)
+	}
+
+	del := adapter.NewDeleteCommand(2, logger)
+	doneDel := mustDo(t, del, buffer)
+	if buffer.Content != "こんに" {
+		t.Errorf("Delete failed: %s", buffer.Content)
+	}
+	mustUndo(t, doneDel, buffer)
+	if buffer.Content != "こんにちは" {
+		t.Errorf("Undo Delete failed: %s", buffer.Content)
+	}
+}
+
+func TestInsertAtCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		pos     int
+		text    string
+		want    string
+	}{
+		{"Start", "World", 0, "Hello ", "Hello World"},
+		{"Middle", "Helo", 2, "l", "Hello"},
+		{"MultiByte", "日本", 1, "の", "日の本"},
+		{"PastEnd", "abc", 10, "d", "abcd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := domain.NewBuffer()
+			buffer.Content = tt.content
+			buffer.MoveCursor(1)
+			cmd := adapter.NewInsertAtCommand(tt.pos, tt.text, &MockLogger{})
+
+			done := mustDo(t, cmd, buffer)
+			if buffer.Content != tt.want {
+				t.Errorf("InsertAt failed: got %q, want %q", buffer.Content, tt.want)
+			}
+
+			mustUndo(t, done, buffer)
+			if buffer.Content != tt.content {
+				t.Errorf("Undo InsertAt failed: got %q, want %q", buffer.Content, tt.content)
+			}
+			if buffer.Cursor != 1 || buffer.Anchor != 1 {
+				t.Errorf("Undo InsertAt did not restore cursor: cursor=%d anchor=%d", buffer.Cursor, buffer.Anchor)
+			}
+		})
+	}
+}
+
+func TestDeleteRangeCommand(t *testing.T) {
+	buffer := domain.NewBuffer()
+	buffer.Content = "ありがとう"
+	buffer.MoveCursor(4)
+	cmd := adapter.NewDeleteRangeCommand(1, 3, &MockLogger{})
+
+	done := mustDo(t, cmd, buffer)
+	if buffer.Content != "あとう" {
+		t.Errorf("DeleteRange failed: %s", buffer.Content)
+	}
+	if buffer.Cursor != 2 {
+		t.Errorf("expected cursor to shift to 2, got %d", buffer.Cursor)
+	}
+
+	mustUndo(t, done, buffer)
+	if buffer.Content != "ありがとう" {
+		t.Errorf("Undo DeleteRange failed: %s", buffer.Content)
+	}
+	if buffer.Cursor != 4 {
+		t.Errorf("expected cursor restored to 4, got %d", buffer.Cursor)
+	}
+}
+
+func TestMoveCursorCommand(t *testing.T) {
+	buffer := domain.NewBuffer()
+	buffer.Content = "Hello"
+	buffer.Select(1, 3)
+	cmd := adapter.NewMoveCursorCommand(5, &MockLogger{})
+
+	done := mustDo(t, cmd, buffer)
+	if buffer.Cursor != 5 || buffer.HasSelection() {
+		t.Errorf("MoveCursor failed: cursor=%d selection=%v", buffer.Cursor, buffer.HasSelection())
+	}
+
+	mustUndo(t, done, buffer)
+	if start, end := buffer.Selection(); start != 1 || end != 3 {
+		t.Errorf("Undo MoveCursor did not restore selection: [%d,%d)", start, end)
+	}
+}
+
+func TestReplaceSelectionCommand(t *testing.T) {
+	logger := &MockLogger{}
+	buffer := domain.NewBuffer()
+	buffer.Content = "Hello World"
+
+	sel := adapter.NewSelectCommand(6, 11, logger)
+	doneSel := mustDo(t, sel, buffer)
+
+	cmd := adapter.NewReplaceSelectionCommand("世界", logger)
+	done := mustDo(t, cmd, buffer)
+	if buffer.Content != "Hello 世界" {
+		t.Errorf("ReplaceSelection failed: %s", buffer.Content)
+	}
+	if buffer.Cursor != 8 || buffer.HasSelection() {
+		t.Errorf("expected cursor after replacement, got cursor=%d anchor=%d", buffer.Cursor, buffer.Anchor)
+	}
+
+	mustUndo(t, done, buffer)
+	if buffer.Content != "Hello World" {
+		t.Errorf("Undo ReplaceSelection failed: %s", buffer.Content)
+	}
+	if start, end := buffer.Selection(); start != 6 || end != 11 {
+		t.Errorf("Undo ReplaceSelection did not restore selection: [%d,%d)", start, end)
+	}
+
+	mustUndo(t, doneSel, buffer)
+	if buffer.HasSelection() {
+		t.Error("Undo Select should clear the selection")
+	}
+}
+
+func TestReplaceSelectionCommand_NoSelectionInsertsAtCursor(t *testing.T) {
+	buffer := domain.NewBuffer()
+	buffer.Content = "ac"
+	buffer.MoveCursor(1)
+	cmd := adapter.NewReplaceSelectionCommand("b", &MockLogger{})
+
+	done := mustDo(t, cmd, buffer)
+	if buffer.Content != "abc" || buffer.Cursor != 2 {
+		t.Errorf("ReplaceSelection failed: %q cursor=%d", buffer.Content, buffer.Cursor)
+	}
+
+	mustUndo(t, done, buffer)
+	if buffer.Content != "ac" || buffer.Cursor != 1 {
+		t.Errorf("Undo ReplaceSelection failed: %q cursor=%d", buffer.Content, buffer.Cursor)
+	}
+}
+
+func TestDeleteCommand_ReExecuteIsSafe(t *testing.T) {
+	buffer := domain.NewBuffer()
+	buffer.Content = "abcdef"
+	cmd := adapter.NewDeleteCommand(2, &MockLogger{})
+
+	first := mustDo(t, cmd, buffer)
+	second := mustDo(t, cmd, buffer)
+	if buffer.Content != "ab" {
+		t.Fatalf("expected %q, got %q", "ab", buffer.Content)
+	}
+
+	mustUndo(t, second, buffer)
+	if buffer.Content != "abcd" {
+		t.Errorf("expected %q, got %q", "abcd", buffer.Content)
+	}
+	mustUndo(t, first, buffer)
+	if buffer.Content != "abcdef" {
+		t.Errorf("expected %q, got %q", "abcdef", buffer.Content)
+	}
+}
+
+func TestDeleteCommand_ClampsWithoutMutating(t *testing.T) {
+	buffer := domain.NewBuffer()
+	buffer.Content = "ab"
+	cmd := adapter.NewDeleteCommand(5, &MockLogger{})
+
+	mustDo(t, cmd, buffer)
+	buffer.Content = "abcdefg"
+	mustDo(t, cmd, buffer)
+	if buffer.Content != "ab" {
+		t.Errorf("expected the original count to be kept, got %q", buffer.Content)
+	}
+}
+
+func TestDeleteCommand_NegativeCount(t *testing.T) {
+	buffer := domain.NewBuffer()
+	buffer.Content = "abc"
+
+	_, err := adapter.NewDeleteCommand(-1, &MockLogger{}).Do(buffer)
+	if !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+	if buffer.Content != "abc" {
+		t.Errorf("buffer should be untouched, got %q", buffer.Content)
+	}
+}
+
+func TestUndo_BufferMismatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		initial string
+		cmd     domain.Command
+		changed string // Content someone else left behind
+	}{
+		{"Insert", "", adapter.NewInsertCommand("World", &MockLogger{}), "x"},
+		{"InsertAt", "", adapter.NewInsertAtCommand(0, "Hi", &MockLogger{}), "x"},
+		{"ReplaceSelection", "", adapter.NewReplaceSelectionCommand("Hey", &MockLogger{}), "x"},
+		{"Delete", "hello", adapter.NewDeleteCommand(1, &MockLogger{}), "x"},
+		{"Delete After Append", "hello", adapter.NewDeleteCommand(1, &MockLogger{}), "hellX"},
+		{"DeleteRange", "hello", adapter.NewDeleteRangeCommand(1, 3, &MockLogger{}), "x"},
+		{"DeleteRange After Append", "hello", adapter.NewDeleteRangeCommand(1, 3, &MockLogger{}), "hloX"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := domain.NewBuffer()
+			buffer.InsertAt(0, tt.initial)
+			done := mustDo(t, tt.cmd, buffer)
+
+			// Someone else changed the buffer behind the command's back
+			buffer.Content = tt.changed
+			buffer.MoveCursor(0)
+
+			err := done.Undo(buffer)
+			if !errors.Is(err, domain.ErrBufferMismatch) {
+				t.Errorf("expected ErrBufferMismatch, got %v", err)
+			}
+			if buffer.Content != tt.changed {
+				t.Errorf("buffer should be untouched, got %q", buffer.Content)
+			}
+		})
+	}
+}
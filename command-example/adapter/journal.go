@@ -0,0 +1,175 @@
+package adapter
+
+import (
+	"bytes"
+	"command-example/domain"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// Ensure implementation
+var _ domain.Journal = (*FileJournal)(nil)
+
+// FileJournal stores editor operations as JSON Lines.
+// The snapshot lives next to the journal in "<path>.snapshot".
+//
+// Compaction writes the snapshot before truncating the journal. If the process
+// crashes in between, Load skips the entries the snapshot already covers.
+type FileJournal struct {
+	path         string
+	snapshotPath string
+	seq          int64
+	seqLoaded    bool
+}
+
+// NewFileJournal creates a journal backed by the file at path.
+func NewFileJournal(path string) *FileJournal {
+	return &FileJournal{
+		path:         path,
+		snapshotPath: path + ".snapshot",
+	}
+}
+
+// Append writes one entry and syncs it to disk.
+func (j *FileJournal) Append(e domain.JournalEntry) error {
+	if err := j.ensureSeq(); err != nil {
+		return err
+	}
+	e.Seq = j.seq + 1
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+	j.seq = e.Seq
+	return nil
+}
+
+// Load returns the snapshot and the entries recorded after it.
+// A torn final line (a crash in the middle of Append) is discarded.
+func (j *FileJournal) Load() (domain.Snapshot, []domain.JournalEntry, error) {
+	snap, err := j.readSnapshot()
+	if err != nil {
+		return domain.Snapshot{}, nil, err
+	}
+
+	all, err := j.readEntries()
+	if err != nil {
+		return domain.Snapshot{}, nil, err
+	}
+
+	j.seq = snap.Seq
+	var entries []domain.JournalEntry
+	for _, e := range all {
+		j.seq = max(j.seq, e.Seq)
+		if e.Seq > snap.Seq {
+			entries = append(entries, e)
+		}
+	}
+	j.seqLoaded = true
+	return snap, entries, nil
+}
+
+// Compact replaces the snapshot and empties the journal.
+func (j *FileJournal) Compact(s domain.Snapshot) error {
+	if err := j.ensureSeq(); err != nil {
+		return err
+	}
+	s.Seq = j.seq
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("journal snapshot: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a half-written snapshot.
+	tmp := j.snapshotPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("journal snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, j.snapshotPath); err != nil {
+		return fmt.Errorf("journal snapshot: %w", err)
+	}
+
+	if err := os.Truncate(j.path, 0); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("journal: %w", err)
+	}
+	return nil
+}
+
+// ensureSeq recovers the last sequence number before the first write.
+func (j *FileJournal) ensureSeq() error {
+	if j.seqLoaded {
+		return nil
+	}
+	_, _, err := j.Load()
+	return err
+}
+
+func (j *FileJournal) readSnapshot() (domain.Snapshot, error) {
+	var snap domain.Snapshot
+	data, err := os.ReadFile(j.snapshotPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return snap, nil
+	}
+	if err != nil {
+		return snap, fmt.Errorf("journal snapshot: %w", err)
+	}
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return snap, fmt.Errorf("journal snapshot: %w", err)
+	}
+	return snap, nil
+}
+
+func (j *FileJournal) readEntries() ([]domain.JournalEntry, error) {
+	data, err := os.ReadFile(j.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("journal: %w", err)
+	}
+
+	var entries []domain.JournalEntry
+	offset := 0
+	for line := 1; offset < len(data); line++ {
+		end := bytes.IndexByte(data[offset:], '\n')
+		if end < 0 {
+			// A line without its newline was never acknowledged by Append.
+			// Drop it so the next Append starts on a clean line.
+			if err := os.Truncate(j.path, int64(offset)); err != nil {
+				return nil, fmt.Errorf("journal: %w", err)
+			}
+			break
+		}
+
+		raw := data[offset : offset+end]
+		offset += end + 1
+		if len(bytes.TrimSpace(raw)) == 0 {
+			continue
+		}
+
+		var e domain.JournalEntry
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return nil, fmt.Errorf("journal: corrupt entry at line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
@@ -0,0 +1,124 @@
+package adapter_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"command-example/adapter"
+	"command-example/domain"
+)
+
+func mustAppend(t *testing.T, j domain.Journal, op domain.JournalOp) {
+	t.Helper()
+	if err := j.Append(domain.JournalEntry{Op: op}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+}
+
+func TestFileJournal_AppendAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "editor.jsonl")
+	journal := adapter.NewFileJournal(path)
+
+	mustAppend(t, journal, domain.OpUndo)
+	mustAppend(t, journal, domain.OpRedo)
+
+	// A fresh instance continues the sequence after reading the file
+	reopened := adapter.NewFileJournal(path)
+	mustAppend(t, reopened, domain.OpUndo)
+
+	_, entries, err := reopened.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	for i, e := range entries {
+		if e.Seq != int64(i+1) {
+			t.Errorf("entry %d: expected seq %d, got %d", i, i+1, e.Seq)
+		}
+	}
+}
+
+func TestFileJournal_DiscardsTornLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "editor.jsonl")
+	journal := adapter.NewFileJournal(path)
+	mustAppend(t, journal, domain.OpUndo)
+
+	// Simulate a crash in the middle of a write
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"seq":2,"op":"re`)
+	f.Close()
+
+	recovered := adapter.NewFileJournal(path)
+	_, entries, err := recovered.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected the torn line to be dropped, got %d entries", len(entries))
+	}
+
+	mustAppend(t, recovered, domain.OpRedo)
+	if _, entries, err = recovered.Load(); err != nil || len(entries) != 2 {
+		t.Fatalf("expected 2 clean entries after append, got %d (err=%v)", len(entries), err)
+	}
+}
+
+func TestFileJournal_RejectsCorruptEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "editor.jsonl")
+	if err := os.WriteFile(path, []byte("garbage\n{\"seq\":1,\"op\":\"undo\"}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := adapter.NewFileJournal(path).Load(); err == nil {
+		t.Error("expected an error for a corrupt entry")
+	}
+}
+
+func TestFileJournal_Compact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "editor.jsonl")
+	journal := adapter.NewFileJournal(path)
+	mustAppend(t, journal, domain.OpUndo)
+	mustAppend(t, journal, domain.OpRedo)
+
+	if err := journal.Compact(domain.Snapshot{Content: "Hello", Cursor: 5, Anchor: 5}); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	mustAppend(t, journal, domain.OpUndo)
+
+	snap, entries, err := adapter.NewFileJournal(path).Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if snap.Content != "Hello" || snap.Seq != 2 {
+		t.Errorf("unexpected snapshot: %+v", snap)
+	}
+	if len(entries) != 1 || entries[0].Seq != 3 {
+		t.Errorf("expected only the entry after the snapshot, got %+v", entries)
+	}
+}
+
+func TestFileJournal_SkipsEntriesCoveredBySnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "editor.jsonl")
+	journal := adapter.NewFileJournal(path)
+	mustAppend(t, journal, domain.OpUndo)
+	mustAppend(t, journal, domain.OpRedo)
+
+	// Simulate a crash after the snapshot was written but before the journal was truncated
+	if err := os.WriteFile(path+".snapshot", []byte(`{"seq":1,"content":"Hi"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	snap, entries, err := adapter.NewFileJournal(path).Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if snap.Content != "Hi" || len(entries) != 1 || entries[0].Op != domain.OpRedo {
+		t.Errorf("unexpected load result: snap=%+v entries=%+v", snap, entries)
+	}
+}
@@ -0,0 +1,76 @@
+package adapter
+
+import (
+	"command-example/domain"
+	"unicode/utf8"
+)
+
+// Ensure implementation
+var (
+	_ domain.Mergeable = InsertCommand{}
+	_ domain.Mergeable = DeleteCommand{}
+	_ domain.Mergeable = InsertAtCommand{}
+	_ domain.Mergeable = DeleteRangeCommand{}
+)
+
+// Merging happens on applied commands: the merged command keeps the caret saved
+// by the first one and the combined text, so a single Undo reverts the whole run.
+
+// MergeWith joins consecutive appends ("h", "e", "l", ...).
+func (c InsertCommand) MergeWith(next domain.Command) (domain.Command, bool) {
+	n, ok := next.(InsertCommand)
+	if !ok || n.insertedAt != c.insertedAt+utf8.RuneCountInString(c.textToInsert) {
+		return nil, false
+	}
+	c.textToInsert += n.textToInsert
+	return c, true
+}
+
+// MergeWith joins consecutive deletions from the end (repeated backspace).
+// The next deletion must end where this one started, i.e. nothing was added
+// to the end in between.
+func (c DeleteCommand) MergeWith(next domain.Command) (domain.Command, bool) {
+	n, ok := next.(DeleteCommand)
+	if !ok || n.deletedAt+utf8.RuneCountInString(n.deletedText) != c.deletedAt {
+		return nil, false
+	}
+	c.count += n.count
+	c.deletedAt = n.deletedAt
+	c.deletedText = n.deletedText + c.deletedText
+	c.after = n.after
+	return c, true
+}
+
+// MergeWith joins inserts typed one after another at the same spot.
+func (c InsertAtCommand) MergeWith(next domain.Command) (domain.Command, bool) {
+	n, ok := next.(InsertAtCommand)
+	if !ok || n.insertedAt != c.insertedAt+utf8.RuneCountInString(c.textToInsert) {
+		return nil, false
+	}
+	c.textToInsert += n.textToInsert
+	return c, true
+}
+
+// MergeWith joins adjacent range deletions, both backspace (the next range ends
+// where this one started) and forward delete (both start at the same offset).
+func (c DeleteRangeCommand) MergeWith(next domain.Command) (domain.Command, bool) {
+	n, ok := next.(DeleteRangeCommand)
+	if !ok {
+		return nil, false
+	}
+
+	length := utf8.RuneCountInString(n.deletedText)
+	switch {
+	case n.deletedAt+length == c.deletedAt: // Backspace
+		c.deletedAt = n.deletedAt
+		c.deletedText = n.deletedText + c.deletedText
+	case n.deletedAt == c.deletedAt: // Forward delete
+		c.deletedText += n.deletedText
+	default:
+		return nil, false
+	}
+	c.start = c.deletedAt
+	c.end = c.deletedAt + utf8.RuneCountInString(c.deletedText)
+	c.after = n.after
+	return c, true
+}
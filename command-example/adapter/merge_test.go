@@ -0,0 +1,140 @@
+package adapter_test
+
+import (
+	"testing"
+
+	"command-example/adapter"
+	"command-example/domain"
+)
+
+// mergeAll applies cmds in order and folds them into one applied command.
+func mergeAll(t *testing.T, buffer *domain.Buffer, cmds ...domain.Command) domain.Command {
+	t.Helper()
+	merged := mustDo(t, cmds[0], buffer)
+	for _, c := range cmds[1:] {
+		next := mustDo(t, c, buffer)
+		m, ok := merged.(domain.Mergeable)
+		if !ok {
+			t.Fatalf("%T is not mergeable", merged)
+		}
+		if merged, ok = m.MergeWith(next); !ok {
+			t.Fatalf("expected %T to merge", next)
+		}
+	}
+	return merged
+}
+
+func TestMerge_Typing(t *testing.T) {
+	logger := &MockLogger{}
+	buffer := domain.NewBuffer()
+	buffer.Content = "> "
+	buffer.MoveCursor(2)
+
+	merged := mergeAll(t, buffer,
+		adapter.NewInsertCommand("h", logger),
+		adapter.NewInsertCommand("é", logger),
+		adapter.NewInsertCommand("llo", logger),
+	)
+	if buffer.Content != "> héllo" {
+		t.Fatalf("unexpected content: %q", buffer.Content)
+	}
+
+	mustUndo(t, merged, buffer)
+	if buffer.Content != "> " || buffer.Cursor != 2 {
+		t.Errorf("expected a single undo to revert the run, got %q cursor=%d", buffer.Content, buffer.Cursor)
+	}
+}
+
+func TestMerge_Backspace(t *testing.T) {
+	logger := &MockLogger{}
+	buffer := domain.NewBuffer()
+	buffer.Content = "Hello"
+
+	merged := mergeAll(t, buffer,
+		adapter.NewDeleteCommand(1, logger),
+		adapter.NewDeleteCommand(1, logger),
+	)
+	if buffer.Content != "Hel" {
+		t.Fatalf("unexpected content: %q", buffer.Content)
+	}
+
+	mustUndo(t, merged, buffer)
+	if buffer.Content != "Hello" {
+		t.Errorf("expected %q, got %q", "Hello", buffer.Content)
+	}
+
+	// Text added to the end in between
+	first := mustDo(t, adapter.NewDeleteCommand(1, logger), buffer)
+	buffer.InsertAt(buffer.Len(), "!?")
+	second := mustDo(t, adapter.NewDeleteCommand(1, logger), buffer)
+	if _, ok := first.(domain.Mergeable).MergeWith(second); ok {
+		t.Error("non-adjacent deletions should not merge")
+	}
+}
+
+func TestMerge_InsertAt(t *testing.T) {
+	logger := &MockLogger{}
+	buffer := domain.NewBuffer()
+	buffer.Content = "ac"
+
+	merged := mergeAll(t, buffer,
+		adapter.NewInsertAtCommand(1, "b", logger),
+		adapter.NewInsertAtCommand(2, "b", logger),
+	)
+	if buffer.Content != "abbc" {
+		t.Fatalf("unexpected content: %q", buffer.Content)
+	}
+	mustUndo(t, merged, buffer)
+	if buffer.Content != "ac" {
+		t.Errorf("expected %q, got %q", "ac", buffer.Content)
+	}
+
+	// Not adjacent
+	first := mustDo(t, adapter.NewInsertAtCommand(0, "x", logger), buffer)
+	second := mustDo(t, adapter.NewInsertAtCommand(3, "y", logger), buffer)
+	if _, ok := first.(domain.Mergeable).MergeWith(second); ok {
+		t.Error("non-adjacent inserts should not merge")
+	}
+}
+
+func TestMerge_DeleteRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		ranges [][2]int
+		want   string
+	}{
+		{"Backspace", [][2]int{{4, 5}, {3, 4}, {2, 3}}, "Wo"},
+		{"ForwardDelete", [][2]int{{1, 2}, {1, 2}}, "Wld"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := domain.NewBuffer()
+			buffer.Content = "World"
+			var cmds []domain.Command
+			for _, r := range tt.ranges {
+				cmds = append(cmds, adapter.NewDeleteRangeCommand(r[0], r[1], &MockLogger{}))
+			}
+
+			merged := mergeAll(t, buffer, cmds...)
+			if buffer.Content != tt.want {
+				t.Fatalf("unexpected content: %q", buffer.Content)
+			}
+			mustUndo(t, merged, buffer)
+			if buffer.Content != "World" {
+				t.Errorf("expected %q, got %q", "World", buffer.Content)
+			}
+		})
+	}
+}
+
+func TestMerge_DifferentTypes(t *testing.T) {
+	logger := &MockLogger{}
+	buffer := domain.NewBuffer()
+
+	insert := mustDo(t, adapter.NewInsertCommand("ab", logger), buffer)
+	del := mustDo(t, adapter.NewDeleteCommand(1, logger), buffer)
+	if _, ok := insert.(domain.Mergeable).MergeWith(del); ok {
+		t.Error("insert and delete should not merge")
+	}
+}
@@ -0,0 +1,188 @@
+package adapter
+
+import (
+	"command-example/domain"
+	"encoding/json"
+	"fmt"
+)
+
+// Ensure implementation
+var _ domain.CommandCodec = (*Registry)(nil)
+
+// Record type names of the built-in commands.
+const (
+	InsertType           = "insert"
+	DeleteType           = "delete"
+	InsertAtType         = "insert_at"
+	DeleteRangeType      = "delete_range"
+	MoveCursorType       = "move_cursor"
+	SelectType           = "select"
+	ReplaceSelectionType = "replace_selection"
+)
+
+// Registry maps record type names to command factories.
+// New commands become journal-aware by registering a factory under their type name.
+type Registry struct {
+	factories map[string]domain.CommandFactory
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]domain.CommandFactory)}
+}
+
+// NewDefaultRegistry creates a registry with all built-in commands and macros.
+func NewDefaultRegistry(logger domain.Logger) *Registry {
+	r := NewRegistry()
+	r.Register(InsertType, func(raw json.RawMessage) (domain.Command, error) {
+		var args textArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return nil, err
+		}
+		return NewInsertCommand(args.Text, logger), nil
+	})
+	r.Register(DeleteType, func(raw json.RawMessage) (domain.Command, error) {
+		var args countArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return nil, err
+		}
+		return NewDeleteCommand(args.Count, logger), nil
+	})
+	r.Register(InsertAtType, func(raw json.RawMessage) (domain.Command, error) {
+		var args posTextArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return nil, err
+		}
+		return NewInsertAtCommand(args.Pos, args.Text, logger), nil
+	})
+	r.Register(DeleteRangeType, func(raw json.RawMessage) (domain.Command, error) {
+		var args rangeArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return nil, err
+		}
+		return NewDeleteRangeCommand(args.Start, args.End, logger), nil
+	})
+	r.Register(MoveCursorType, func(raw json.RawMessage) (domain.Command, error) {
+		var args posArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return nil, err
+		}
+		return NewMoveCursorCommand(args.Pos, logger), nil
+	})
+	r.Register(SelectType, func(raw json.RawMessage) (domain.Command, error) {
+		var args rangeArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return nil, err
+		}
+		return NewSelectCommand(args.Start, args.End, logger), nil
+	})
+	r.Register(ReplaceSelectionType, func(raw json.RawMessage) (domain.Command, error) {
+		var args textArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return nil, err
+		}
+		return NewReplaceSelectionCommand(args.Text, logger), nil
+	})
+	r.Register(domain.MacroType, func(raw json.RawMessage) (domain.Command, error) {
+		var args domain.MacroArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return nil, err
+		}
+		macro := domain.NewMacroCommand(args.Name)
+		for _, rec := range args.Commands {
+			c, err := r.Decode(rec)
+			if err != nil {
+				return nil, err
+			}
+			macro.Add(c)
+		}
+		return macro, nil
+	})
+	return r
+}
+
+// Register adds (or replaces) the factory for a type name.
+func (r *Registry) Register(typ string, factory domain.CommandFactory) {
+	r.factories[typ] = factory
+}
+
+// Encode converts a command into a record.
+func (r *Registry) Encode(c domain.Command) (domain.CommandRecord, error) {
+	s, ok := c.(domain.Serializable)
+	if !ok {
+		return domain.CommandRecord{}, fmt.Errorf("%w: %T", domain.ErrNotSerializable, c)
+	}
+	rec, err := s.Record()
+	if err != nil {
+		return domain.CommandRecord{}, err
+	}
+	if _, ok := r.factories[rec.Type]; !ok {
+		return domain.CommandRecord{}, fmt.Errorf("%w: %s", domain.ErrUnknownCommand, rec.Type)
+	}
+	return rec, nil
+}
+
+// Decode rebuilds a command from a record.
+func (r *Registry) Decode(rec domain.CommandRecord) (domain.Command, error) {
+	factory, ok := r.factories[rec.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnknownCommand, rec.Type)
+	}
+	c, err := factory(rec.Args)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", rec.Type, err)
+	}
+	return c, nil
+}
+
+// --- Serialized parameters ---
+
+type textArgs struct {
+	Text string `json:"text"`
+}
+
+type countArgs struct {
+	Count int `json:"count"`
+}
+
+type posArgs struct {
+	Pos int `json:"pos"`
+}
+
+type posTextArgs struct {
+	Pos  int    `json:"pos"`
+	Text string `json:"text"`
+}
+
+type rangeArgs struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+func (c InsertCommand) Record() (domain.CommandRecord, error) {
+	return domain.NewCommandRecord(InsertType, textArgs{Text: c.textToInsert})
+}
+
+func (c DeleteCommand) Record() (domain.CommandRecord, error) {
+	return domain.NewCommandRecord(DeleteType, countArgs{Count: c.count})
+}
+
+func (c InsertAtCommand) Record() (domain.CommandRecord, error) {
+	return domain.NewCommandRecord(InsertAtType, posTextArgs{Pos: c.pos, Text: c.textToInsert})
+}
+
+func (c DeleteRangeCommand) Record() (domain.CommandRecord, error) {
+	return domain.NewCommandRecord(DeleteRangeType, rangeArgs{Start: c.start, End: c.end})
+}
+
+func (c MoveCursorCommand) Record() (domain.CommandRecord, error) {
+	return domain.NewCommandRecord(MoveCursorType, posArgs{Pos: c.pos})
+}
+
+func (c SelectCommand) Record() (domain.CommandRecord, error) {
+	return domain.NewCommandRecord(SelectType, rangeArgs{Start: c.start, End: c.end})
+}
+
+func (c ReplaceSelectionCommand) Record() (domain.CommandRecord, error) {
+	return domain.NewCommandRecord(ReplaceSelectionType, textArgs{Text: c.replacement})
+}
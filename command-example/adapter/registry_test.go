@@ -0,0 +1,71 @@
+package adapter_test
+
+import (
+	"errors"
+	"testing"
+
+	"command-example/adapter"
+	"command-example/domain"
+)
+
+type unserializableCommand struct{}
+
+func (u unserializableCommand) Do(b *domain.Buffer) (domain.Command, error) { return u, nil }
+func (u unserializableCommand) Undo(b *domain.Buffer) error                 { return nil }
+
+func TestRegistry_RoundTrip(t *testing.T) {
+	logger := &MockLogger{}
+	registry := adapter.NewDefaultRegistry(logger)
+
+	tests := []struct {
+		name string
+		cmd  domain.Command
+		want string
+	}{
+		{"Insert", adapter.NewInsertCommand("!", logger), "Hello World!"},
+		{"Delete", adapter.NewDeleteCommand(6, logger), "Hello"},
+		{"InsertAt", adapter.NewInsertAtCommand(5, ",", logger), "Hello, World"},
+		{"DeleteRange", adapter.NewDeleteRangeCommand(0, 6, logger), "World"},
+		{"MoveCursor", adapter.NewMoveCursorCommand(3, logger), "Hello World"},
+		{"Select", adapter.NewSelectCommand(0, 5, logger), "Hello World"},
+		{"ReplaceSelection", adapter.NewReplaceSelectionCommand("Hi ", logger), "Hi Hello World"},
+		{"Macro", domain.NewMacroCommand("m",
+			adapter.NewSelectCommand(6, 11, logger),
+			adapter.NewReplaceSelectionCommand("世界", logger),
+		), "Hello 世界"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := registry.Encode(tt.cmd)
+			if err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+			decoded, err := registry.Decode(rec)
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+
+			buffer := domain.NewBuffer()
+			buffer.Content = "Hello World"
+			mustDo(t, decoded, buffer)
+			if buffer.Content != tt.want {
+				t.Errorf("decoded command produced %q, want %q", buffer.Content, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry_Errors(t *testing.T) {
+	registry := adapter.NewRegistry()
+
+	if _, err := registry.Encode(unserializableCommand{}); !errors.Is(err, domain.ErrNotSerializable) {
+		t.Errorf("expected ErrNotSerializable, got %v", err)
+	}
+	if _, err := registry.Encode(adapter.NewInsertCommand("x", &MockLogger{})); !errors.Is(err, domain.ErrUnknownCommand) {
+		t.Errorf("expected ErrUnknownCommand for an unregistered type, got %v", err)
+	}
+	if _, err := registry.Decode(domain.CommandRecord{Type: "nope"}); !errors.Is(err, domain.ErrUnknownCommand) {
+		t.Errorf("expected ErrUnknownCommand, got %v", err)
+	}
+}
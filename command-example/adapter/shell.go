@@ -0,0 +1,244 @@
+package adapter
+
+import (
+	"bufio"
+	"command-example/domain"
+	"command-example/usecase"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrUsage is returned when a shell verb gets the wrong arguments.
+var ErrUsage = errors.New("usage")
+
+// Shell is a line-oriented front end for the Editor.
+// Each line is a verb followed by its arguments, e.g. "insert Hello" or "undo".
+// It only turns text into Commands; the Editor still does all the work.
+type Shell struct {
+	editor *usecase.Editor
+	logger domain.Logger
+	out    io.Writer
+	verbs  map[string]verb
+}
+
+type verb struct {
+	usage string
+	run   func(args string) error
+}
+
+// NewShell builds a shell driving editor. Commands it creates log to logger,
+// and the shell's own output goes to out.
+func NewShell(editor *usecase.Editor, logger domain.Logger, out io.Writer) *Shell {
+	s := &Shell{
+		editor: editor,
+		logger: logger,
+		out:    out,
+	}
+	s.verbs = map[string]verb{
+		"insert":   {"insert <text>", s.insert},
+		"insertat": {"insertat <pos> <text>", s.insertAt},
+		"delete":   {"delete <n>", s.delete},
+		"erase":    {"erase <start> <end>", s.erase},
+		"move":     {"move <pos>", s.move},
+		"select":   {"select <start> <end>", s.selectRange},
+		"replace":  {"replace <text>", s.replace},
+		"begin":    {"begin [name]", s.begin},
+		"end":      {"end", s.noArgs(editor.EndGroup)},
+		"abort":    {"abort", s.noArgs(editor.AbortGroup)},
+		"undo":     {"undo", s.noArgs(editor.Undo)},
+		"redo":     {"redo", s.noArgs(editor.Redo)},
+		"show":     {"show", s.noArgs(s.show)},
+		"history":  {"history", s.noArgs(s.history)},
+		"help":     {"help", s.noArgs(s.help)},
+	}
+	return s
+}
+
+// Run reads lines from in until EOF or "quit". Lines starting with "#" are comments.
+// Errors from individual lines are reported and do not stop the shell.
+func (s *Shell) Run(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	s.prompt()
+	for scanner.Scan() {
+		// Trailing spaces are kept: they may be part of the text to insert
+		line := strings.TrimLeft(strings.TrimRight(scanner.Text(), "\r"), " \t")
+		switch strings.TrimSpace(line) {
+		case "quit", "exit":
+			return nil
+		}
+		if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, "#") {
+			if err := s.Exec(line); err != nil {
+				fmt.Fprintf(s.out, "error: %v\n", err)
+			}
+		}
+		s.prompt()
+	}
+	return scanner.Err()
+}
+
+// Exec runs a single line.
+func (s *Shell) Exec(line string) error {
+	name, args, _ := strings.Cut(line, " ")
+	v, ok := s.verbs[name]
+	if !ok {
+		return fmt.Errorf("unknown command %q (try \"help\")", name)
+	}
+	if err := v.run(args); err != nil {
+		if errors.Is(err, ErrUsage) {
+			return fmt.Errorf("%w: %s", ErrUsage, v.usage)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *Shell) prompt() {
+	fmt.Fprint(s.out, "> ")
+}
+
+// --- Verbs ---
+
+func (s *Shell) insert(args string) error {
+	if args == "" {
+		return ErrUsage
+	}
+	return s.editor.Execute(NewInsertCommand(args, s.logger))
+}
+
+func (s *Shell) insertAt(args string) error {
+	pos, text, err := intAndText(args)
+	if err != nil {
+		return err
+	}
+	return s.editor.Execute(NewInsertAtCommand(pos, text, s.logger))
+}
+
+func (s *Shell) delete(args string) error {
+	n, err := ints(args, 1)
+	if err != nil {
+		return err
+	}
+	return s.editor.Execute(NewDeleteCommand(n[0], s.logger))
+}
+
+func (s *Shell) erase(args string) error {
+	n, err := ints(args, 2)
+	if err != nil {
+		return err
+	}
+	return s.editor.Execute(NewDeleteRangeCommand(n[0], n[1], s.logger))
+}
+
+func (s *Shell) move(args string) error {
+	n, err := ints(args, 1)
+	if err != nil {
+		return err
+	}
+	return s.editor.Execute(NewMoveCursorCommand(n[0], s.logger))
+}
+
+func (s *Shell) selectRange(args string) error {
+	n, err := ints(args, 2)
+	if err != nil {
+		return err
+	}
+	return s.editor.Execute(NewSelectCommand(n[0], n[1], s.logger))
+}
+
+func (s *Shell) replace(args string) error {
+	return s.editor.Execute(NewReplaceSelectionCommand(args, s.logger))
+}
+
+func (s *Shell) begin(args string) error {
+	return s.editor.BeginGroup(args)
+}
+
+func (s *Shell) show() error {
+	fmt.Fprintf(s.out, "\"%s\" (cursor %d)\n", s.editor.GetContent(), s.editor.Cursor())
+	return nil
+}
+
+func (s *Shell) history() error {
+	undo, redo := s.editor.Stacks()
+	if len(undo) == 0 && len(redo) == 0 {
+		fmt.Fprintln(s.out, "(empty)")
+		return nil
+	}
+	for i, c := range undo {
+		fmt.Fprintf(s.out, "  %d. %s\n", i+1, describe(c))
+	}
+	// Redo entries are listed in the order Redo would apply them
+	for i := len(redo) - 1; i >= 0; i-- {
+		fmt.Fprintf(s.out, "  -  %s (undone)\n", describe(redo[i]))
+	}
+	return nil
+}
+
+func (s *Shell) help() error {
+	usages := make([]string, 0, len(s.verbs)+1)
+	for _, v := range s.verbs {
+		usages = append(usages, v.usage)
+	}
+	usages = append(usages, "quit")
+	sort.Strings(usages)
+	for _, u := range usages {
+		fmt.Fprintf(s.out, "  %s\n", u)
+	}
+	return nil
+}
+
+// noArgs adapts a verb that takes no arguments.
+func (s *Shell) noArgs(run func() error) func(string) error {
+	return func(args string) error {
+		if strings.TrimSpace(args) != "" {
+			return ErrUsage
+		}
+		return run()
+	}
+}
+
+// --- Helpers ---
+
+// describe renders a command through its journal record when it has one.
+func describe(c domain.Command) string {
+	if sc, ok := c.(domain.Serializable); ok {
+		if rec, err := sc.Record(); err == nil {
+			return fmt.Sprintf("%s %s", rec.Type, rec.Args)
+		}
+	}
+	return fmt.Sprintf("%T", c)
+}
+
+// ints parses exactly n whitespace-separated integers.
+func ints(args string, n int) ([]int, error) {
+	fields := strings.Fields(args)
+	if len(fields) != n {
+		return nil, ErrUsage
+	}
+	out := make([]int, n)
+	for i, f := range fields {
+		v, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, ErrUsage
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// intAndText parses "<int> <text>", keeping the text verbatim.
+func intAndText(args string) (int, string, error) {
+	head, text, ok := strings.Cut(args, " ")
+	if !ok || text == "" {
+		return 0, "", ErrUsage
+	}
+	pos, err := strconv.Atoi(head)
+	if err != nil {
+		return 0, "", ErrUsage
+	}
+	return pos, text, nil
+}
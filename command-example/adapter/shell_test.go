@@ -0,0 +1,127 @@
+package adapter_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"command-example/adapter"
+	"command-example/usecase"
+)
+
+func runScript(t *testing.T, script string) (*usecase.Editor, string) {
+	t.Helper()
+	logger := &MockLogger{}
+	editor := usecase.NewEditor(logger)
+	var out strings.Builder
+
+	shell := adapter.NewShell(editor, logger, &out)
+	if err := shell.Run(strings.NewReader(script)); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	return editor, out.String()
+}
+
+func TestShell_EditingSession(t *testing.T) {
+	editor, out := runScript(t, `
+insert Hello
+insert  World
+delete 6
+undo
+# comments and blank lines are ignored
+
+select 6 11
+replace 世界
+insertat 0 >> 
+show
+`)
+
+	if got := editor.GetContent(); got != ">> Hello 世界" {
+		t.Errorf("unexpected content: %q", got)
+	}
+	if !strings.Contains(out, `">> Hello 世界" (cursor 11)`) {
+		t.Errorf("show output missing, got:\n%s", out)
+	}
+	if strings.Contains(out, "error:") {
+		t.Errorf("unexpected error output:\n%s", out)
+	}
+}
+
+func TestShell_UndoRedoHistory(t *testing.T) {
+	_, out := runScript(t, `
+insert a
+insert b
+undo
+history
+redo
+redo
+`)
+
+	if !strings.Contains(out, `1. insert {"text":"a"}`) || !strings.Contains(out, `insert {"text":"b"} (undone)`) {
+		t.Errorf("unexpected history output:\n%s", out)
+	}
+}
+
+func TestShell_Groups(t *testing.T) {
+	editor, _ := runScript(t, `
+insert Hello
+begin rewrite
+delete 5
+insert Bye
+end
+undo
+`)
+
+	if got := editor.GetContent(); got != "Hello" {
+		t.Errorf("expected the group to be undone at once, got %q", got)
+	}
+}
+
+func TestShell_StopsAtQuit(t *testing.T) {
+	editor, _ := runScript(t, "insert a\nquit\ninsert b\n")
+
+	if got := editor.GetContent(); got != "a" {
+		t.Errorf("expected input after quit to be ignored, got %q", got)
+	}
+}
+
+func TestShell_Errors(t *testing.T) {
+	tests := []struct {
+		line  string
+		usage bool
+	}{
+		{"bogus", false},
+		{"insert", true},
+		{"delete x", true},
+		{"delete 1 2", true},
+		{"select 1", true},
+		{"insertat 1", true},
+		{"undo now", true},
+		{"delete -1", false},
+	}
+
+	logger := &MockLogger{}
+	shell := adapter.NewShell(usecase.NewEditor(logger), logger, &strings.Builder{})
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			err := shell.Exec(tt.line)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if errors.Is(err, adapter.ErrUsage) != tt.usage {
+				t.Errorf("usage error = %v, want %v (err=%v)", !tt.usage, tt.usage, err)
+			}
+		})
+	}
+}
+
+func TestShell_ReportsErrorsAndContinues(t *testing.T) {
+	editor, out := runScript(t, "bogus\ninsert ok\n")
+
+	if !strings.Contains(out, `error: unknown command "bogus"`) {
+		t.Errorf("expected error report, got:\n%s", out)
+	}
+	if editor.GetContent() != "ok" {
+		t.Errorf("expected the shell to keep going, got %q", editor.GetContent())
+	}
+}
@@ -1,9 +1,35 @@
 package domain
 
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalidArgument is returned when a command is built with unusable parameters.
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrBufferMismatch is returned when the buffer no longer matches the state
+	// a command recorded, so it cannot be undone exactly.
+	ErrBufferMismatch = errors.New("buffer does not match command state")
+)
+
 // Buffer is the Receiver in the Command Pattern.
-// It holds the actual state (the text content).
+// It holds the actual state (the text content) together with the cursor.
+// All positions are rune offsets, so multi-byte UTF-8 text is never split.
+//
+// The selection spans from Anchor to Cursor. When both are equal, nothing is selected.
 type Buffer struct {
 	Content string
+	Cursor  int
+	Anchor  int
+	marks   []*Mark
+}
+
+// Mark is a cursor and anchor kept outside the buffer (e.g. another client's
+// caret). Attached marks move with edits just like the buffer's own cursor.
+type Mark struct {
+	Cursor int
+	Anchor int
 }
 
 // NewBuffer creates a new buffer.
@@ -11,11 +37,144 @@ func NewBuffer() *Buffer {
 	return &Buffer{}
 }
 
+// Len returns the length of the content in runes.
+func (b *Buffer) Len() int {
+	return utf8.RuneCountInString(b.Content)
+}
+
+// Clamp limits pos to the valid range [0, Len()].
+func (b *Buffer) Clamp(pos int) int {
+	if pos < 0 {
+		return 0
+	}
+	if n := b.Len(); pos > n {
+		return n
+	}
+	return pos
+}
+
+// Text returns the runes between start and end.
+func (b *Buffer) Text(start, end int) string {
+	start, end = b.normalize(start, end)
+	return string([]rune(b.Content)[start:end])
+}
+
+// InsertAt inserts text at pos and returns the (clamped) position used.
+// A cursor or anchor at or after pos is shifted by the inserted length.
+func (b *Buffer) InsertAt(pos int, text string) int {
+	pos = b.Clamp(pos)
+	runes := []rune(b.Content)
+	b.Content = string(runes[:pos]) + text + string(runes[pos:])
+
+	n := utf8.RuneCountInString(text)
+	shift := func(p int) int {
+		if p >= pos {
+			return p + n
+		}
+		return p
+	}
+	b.Cursor, b.Anchor = shift(b.Cursor), shift(b.Anchor)
+	for _, m := range b.marks {
+		m.Cursor, m.Anchor = shift(m.Cursor), shift(m.Anchor)
+	}
+	return pos
+}
+
+// DeleteRange removes the runes between start and end and returns the deleted text.
+// A cursor or anchor inside the range collapses to start; one after it is shifted back.
+func (b *Buffer) DeleteRange(start, end int) string {
+	start, end = b.normalize(start, end)
+	runes := []rune(b.Content)
+	deleted := string(runes[start:end])
+	b.Content = string(runes[:start]) + string(runes[end:])
+
+	b.Cursor = shiftAfterDelete(b.Cursor, start, end)
+	b.Anchor = shiftAfterDelete(b.Anchor, start, end)
+	for _, m := range b.marks {
+		m.Cursor = shiftAfterDelete(m.Cursor, start, end)
+		m.Anchor = shiftAfterDelete(m.Anchor, start, end)
+	}
+	return deleted
+}
+
+// AttachMark makes m follow subsequent edits.
+func (b *Buffer) AttachMark(m *Mark) {
+	b.marks = append(b.marks, m)
+}
+
+// DetachMark stops m from following edits.
+func (b *Buffer) DetachMark(m *Mark) {
+	for i, other := range b.marks {
+		if other == m {
+			b.marks = append(b.marks[:i], b.marks[i+1:]...)
+			return
+		}
+	}
+}
+
+// MoveCursor places the cursor at pos and clears the selection.
+func (b *Buffer) MoveCursor(pos int) {
+	pos = b.Clamp(pos)
+	b.Cursor = pos
+	b.Anchor = pos
+}
+
+// Select selects the runes between start and end, leaving the cursor at end.
+func (b *Buffer) Select(start, end int) {
+	b.Anchor = b.Clamp(start)
+	b.Cursor = b.Clamp(end)
+}
+
+// Selection returns the selected range in ascending order.
+func (b *Buffer) Selection() (start, end int) {
+	if b.Anchor < b.Cursor {
+		return b.Anchor, b.Cursor
+	}
+	return b.Cursor, b.Anchor
+}
+
+// HasSelection reports whether any text is selected.
+func (b *Buffer) HasSelection() bool {
+	return b.Anchor != b.Cursor
+}
+
+func (b *Buffer) normalize(start, end int) (int, int) {
+	start, end = b.Clamp(start), b.Clamp(end)
+	if start > end {
+		start, end = end, start
+	}
+	return start, end
+}
+
+func shiftAfterDelete(pos, start, end int) int {
+	switch {
+	case pos >= end:
+		return pos - (end - start)
+	case pos > start:
+		return start
+	default:
+		return pos
+	}
+}
+
 // Command interface defines the contract for all editor operations.
 // It operates on the Buffer (Receiver).
+//
+// Commands are immutable: Do never modifies the command itself. Instead it
+// returns the applied command, a copy carrying whatever state Undo needs
+// (e.g. the deleted text). The Invoker records that copy, so the same command
+// value can be executed any number of times.
 type Command interface {
-	Do(b *Buffer)
-	Undo(b *Buffer)
+	Do(b *Buffer) (Command, error)
+	Undo(b *Buffer) error
+}
+
+// Mergeable is implemented by applied commands that can absorb the command
+// applied right after them, so a run of keystrokes becomes one undo step.
+type Mergeable interface {
+	// MergeWith returns a single applied command equivalent to the receiver
+	// followed by next, or false if the two are not adjacent.
+	MergeWith(next Command) (Command, bool)
 }
 
 // Logger defines the interface for logging.
@@ -0,0 +1,81 @@
+package domain_test
+
+import (
+	"testing"
+
+	"command-example/domain"
+)
+
+func TestBuffer_InsertAtShiftsCursor(t *testing.T) {
+	b := domain.NewBuffer()
+	b.Content = "héllo"
+	b.MoveCursor(3)
+
+	pos := b.InsertAt(1, "ü")
+	if pos != 1 || b.Content != "hüéllo" {
+		t.Fatalf("unexpected insert result: pos=%d content=%q", pos, b.Content)
+	}
+	if b.Cursor != 4 {
+		t.Errorf("expected cursor 4, got %d", b.Cursor)
+	}
+
+	// Inserting after the cursor leaves it in place
+	b.InsertAt(10, "!")
+	if b.Content != "hüéllo!" || b.Cursor != 4 {
+		t.Errorf("unexpected state: content=%q cursor=%d", b.Content, b.Cursor)
+	}
+}
+
+func TestBuffer_DeleteRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end int
+		cursor     int
+		wantText   string
+		wantDel    string
+		wantCursor int
+	}{
+		{"CursorAfterRange", 0, 2, 4, "ぷる", "ぷよ", 2},
+		{"CursorInsideRange", 1, 3, 2, "ぷる", "よぷ", 1},
+		{"CursorBeforeRange", 2, 4, 1, "ぷよ", "ぷる", 1},
+		{"ReversedRange", 2, 0, 0, "ぷる", "ぷよ", 0},
+		{"OutOfRange", -5, 99, 2, "", "ぷよぷる", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := domain.NewBuffer()
+			b.Content = "ぷよぷる"
+			b.MoveCursor(tt.cursor)
+
+			deleted := b.DeleteRange(tt.start, tt.end)
+			if deleted != tt.wantDel || b.Content != tt.wantText {
+				t.Errorf("got deleted=%q content=%q, want deleted=%q content=%q", deleted, b.Content, tt.wantDel, tt.wantText)
+			}
+			if b.Cursor != tt.wantCursor {
+				t.Errorf("got cursor %d, want %d", b.Cursor, tt.wantCursor)
+			}
+		})
+	}
+}
+
+func TestBuffer_Selection(t *testing.T) {
+	b := domain.NewBuffer()
+	b.Content = "Hello"
+
+	b.Select(4, 1)
+	if start, end := b.Selection(); start != 1 || end != 4 {
+		t.Errorf("expected [1,4), got [%d,%d)", start, end)
+	}
+	if !b.HasSelection() {
+		t.Error("expected a selection")
+	}
+	if got := b.Text(b.Selection()); got != "ell" {
+		t.Errorf("expected %q, got %q", "ell", got)
+	}
+
+	b.MoveCursor(2)
+	if b.HasSelection() {
+		t.Error("MoveCursor should clear the selection")
+	}
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnknownCommand is returned when a record names a command type nobody registered.
+	ErrUnknownCommand = errors.New("unknown command type")
+	// ErrNotSerializable is returned when a command cannot be written to a journal.
+	ErrNotSerializable = errors.New("command is not serializable")
+)
+
+// CommandRecord is the serialized form of a command: its type name and parameters.
+// Only the parameters are stored; undo state is rebuilt when the command is replayed.
+type CommandRecord struct {
+	Type string          `json:"type"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+// NewCommandRecord marshals args into a record of the given type.
+func NewCommandRecord(typ string, args any) (CommandRecord, error) {
+	raw, err := json.Marshal(args)
+	if err != nil {
+		return CommandRecord{}, fmt.Errorf("record %s: %w", typ, err)
+	}
+	return CommandRecord{Type: typ, Args: raw}, nil
+}
+
+// Serializable is implemented by commands that can be written to a journal.
+type Serializable interface {
+	Record() (CommandRecord, error)
+}
+
+// CommandFactory builds a command from its serialized parameters.
+type CommandFactory func(args json.RawMessage) (Command, error)
+
+// CommandCodec converts commands to and from records.
+type CommandCodec interface {
+	Encode(c Command) (CommandRecord, error)
+	Decode(rec CommandRecord) (Command, error)
+}
+
+// JournalOp names an editor operation recorded in the journal.
+type JournalOp string
+
+const (
+	OpExecute    JournalOp = "execute"
+	OpUndo       JournalOp = "undo"
+	OpRedo       JournalOp = "redo"
+	OpBeginGroup JournalOp = "begin_group"
+	OpEndGroup   JournalOp = "end_group"
+	OpAbortGroup JournalOp = "abort_group"
+)
+
+// JournalEntry is one line of the journal.
+// Seq is assigned by the Journal and increases monotonically.
+// Merge records whether an executed command was coalesced into the previous
+// history entry, so replay rebuilds the same undo steps regardless of timing.
+type JournalEntry struct {
+	Seq     int64          `json:"seq"`
+	Op      JournalOp      `json:"op"`
+	Command *CommandRecord `json:"command,omitempty"`
+	Merge   bool           `json:"merge,omitempty"`
+	Group   string         `json:"group,omitempty"`
+}
+
+// Snapshot captures the buffer state after all entries up to Seq.
+type Snapshot struct {
+	Seq     int64  `json:"seq"`
+	Content string `json:"content"`
+	Cursor  int    `json:"cursor"`
+	Anchor  int    `json:"anchor"`
+}
+
+// SnapshotOf captures the current state of the buffer.
+func SnapshotOf(b *Buffer) Snapshot {
+	return Snapshot{Content: b.Content, Cursor: b.Cursor, Anchor: b.Anchor}
+}
+
+// Restore loads the snapshot into the buffer.
+func (s Snapshot) Restore(b *Buffer) {
+	b.Content = s.Content
+	b.Cursor = s.Cursor
+	b.Anchor = s.Anchor
+}
+
+// Journal is an append-only log of editor operations.
+type Journal interface {
+	// Append persists an entry and assigns its Seq.
+	Append(e JournalEntry) error
+	// Load returns the latest snapshot and the entries recorded after it.
+	Load() (Snapshot, []JournalEntry, error)
+	// Compact stores the snapshot as of the last appended entry and drops
+	// the entries it covers.
+	Compact(s Snapshot) error
+}
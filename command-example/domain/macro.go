@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MacroType is the record type name of a MacroCommand.
+const MacroType = "macro"
+
+// MacroCommand is a Composite Command.
+// It groups child commands so they are done and undone as a single unit.
+type MacroCommand struct {
+	Name     string
+	commands []Command
+}
+
+// NewMacroCommand creates a macro from the given commands.
+func NewMacroCommand(name string, commands ...Command) *MacroCommand {
+	return &MacroCommand{
+		Name:     name,
+		commands: commands,
+	}
+}
+
+// Add appends a child command to the macro.
+func (m *MacroCommand) Add(c Command) {
+	m.commands = append(m.commands, c)
+}
+
+// Len returns the number of child commands.
+func (m *MacroCommand) Len() int {
+	return len(m.commands)
+}
+
+// MacroArgs is the serialized form of a MacroCommand.
+type MacroArgs struct {
+	Name     string          `json:"name"`
+	Commands []CommandRecord `json:"commands"`
+}
+
+// Record serializes the macro and all of its children.
+func (m *MacroCommand) Record() (CommandRecord, error) {
+	args := MacroArgs{Name: m.Name}
+	for _, c := range m.commands {
+		s, ok := c.(Serializable)
+		if !ok {
+			return CommandRecord{}, fmt.Errorf("macro %q: %w: %T", m.Name, ErrNotSerializable, c)
+		}
+		rec, err := s.Record()
+		if err != nil {
+			return CommandRecord{}, fmt.Errorf("macro %q: %w", m.Name, err)
+		}
+		args.Commands = append(args.Commands, rec)
+	}
+	return NewCommandRecord(MacroType, args)
+}
+
+// Do applies the children in order and returns a macro of the applied children.
+// If a child fails, the children already applied are undone in reverse order,
+// so the buffer is never left half-edited.
+func (m *MacroCommand) Do(b *Buffer) (Command, error) {
+	applied := make([]Command, 0, len(m.commands))
+	for i, c := range m.commands {
+		done, err := c.Do(b)
+		if err != nil {
+			err = fmt.Errorf("macro %q: step %d: %w", m.Name, i+1, err)
+			return nil, errors.Join(err, undoAll(b, applied))
+		}
+		applied = append(applied, done)
+	}
+	return NewMacroCommand(m.Name, applied...), nil
+}
+
+// Undo reverts the children in reverse order.
+// If a child fails, the children already reverted are applied again.
+func (m *MacroCommand) Undo(b *Buffer) error {
+	for i := len(m.commands) - 1; i >= 0; i-- {
+		if err := m.commands[i].Undo(b); err != nil {
+			err = fmt.Errorf("macro %q: undo step %d: %w", m.Name, i+1, err)
+			return errors.Join(err, doAll(b, m.commands[i+1:]))
+		}
+	}
+	return nil
+}
+
+// undoAll reverts commands in reverse order, collecting any failures.
+func undoAll(b *Buffer, commands []Command) error {
+	var errs []error
+	for i := len(commands) - 1; i >= 0; i-- {
+		if err := commands[i].Undo(b); err != nil {
+			errs = append(errs, fmt.Errorf("rollback: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// doAll re-applies commands in order, collecting any failures.
+func doAll(b *Buffer, commands []Command) error {
+	var errs []error
+	for _, c := range commands {
+		if _, err := c.Do(b); err != nil {
+			errs = append(errs, fmt.Errorf("rollback: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,99 @@
+package domain_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"command-example/domain"
+)
+
+type appendCommand struct {
+	text string
+}
+
+func (a *appendCommand) Do(b *domain.Buffer) (domain.Command, error) {
+	b.Content += a.text
+	return a, nil
+}
+
+func (a *appendCommand) Undo(b *domain.Buffer) error {
+	if !strings.HasSuffix(b.Content, a.text) {
+		return domain.ErrBufferMismatch
+	}
+	b.Content = strings.TrimSuffix(b.Content, a.text)
+	return nil
+}
+
+var errBoom = errors.New("boom")
+
+type failingCommand struct {
+	failDo   bool
+	failUndo bool
+}
+
+func (f *failingCommand) Do(b *domain.Buffer) (domain.Command, error) {
+	if f.failDo {
+		return nil, errBoom
+	}
+	return f, nil
+}
+
+func (f *failingCommand) Undo(b *domain.Buffer) error {
+	if f.failUndo {
+		return errBoom
+	}
+	return nil
+}
+
+func TestMacroCommand_DoUndo(t *testing.T) {
+	b := domain.NewBuffer()
+	macro := domain.NewMacroCommand("greet", &appendCommand{text: "Hello"}, &appendCommand{text: " World"})
+
+	done, err := macro.Do(b)
+	if err != nil {
+		t.Fatalf("Macro Do failed: %v", err)
+	}
+	if b.Content != "Hello World" {
+		t.Errorf("Macro Do failed: %q", b.Content)
+	}
+
+	if err := done.Undo(b); err != nil {
+		t.Fatalf("Macro Undo failed: %v", err)
+	}
+	if b.Content != "" {
+		t.Errorf("Macro Undo failed: %q", b.Content)
+	}
+}
+
+func TestMacroCommand_RollbackOnFailure(t *testing.T) {
+	b := domain.NewBuffer()
+	b.Content = "base"
+	macro := domain.NewMacroCommand("broken", &appendCommand{text: "A"}, &appendCommand{text: "B"}, &failingCommand{failDo: true})
+
+	_, err := macro.Do(b)
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("expected the child error, got %v", err)
+	}
+	if b.Content != "base" {
+		t.Errorf("expected applied children to be rolled back, got %q", b.Content)
+	}
+}
+
+func TestMacroCommand_UndoRollbackOnFailure(t *testing.T) {
+	b := domain.NewBuffer()
+	macro := domain.NewMacroCommand("broken", &failingCommand{failUndo: true}, &appendCommand{text: "A"}, &appendCommand{text: "B"})
+
+	done, err := macro.Do(b)
+	if err != nil {
+		t.Fatalf("Macro Do failed: %v", err)
+	}
+
+	err = done.Undo(b)
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("expected the child error, got %v", err)
+	}
+	if b.Content != "AB" {
+		t.Errorf("expected reverted children to be re-applied, got %q", b.Content)
+	}
+}
@@ -1,14 +1,43 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
 	"command-example/adapter"
+	"command-example/domain"
 	"command-example/usecase"
 )
 
 func main() {
+	interactive := flag.Bool("i", false, "start an interactive shell instead of the demo")
+	flag.Parse()
+
 	logger := adapter.NewConsoleLogger()
+	if *interactive {
+		runShell(logger)
+		return
+	}
+	runDemo(logger)
+}
+
+// runShell drives the editor from stdin, one command per line.
+func runShell(logger domain.Logger) {
+	editor := usecase.NewEditor(logger)
+	shell := adapter.NewShell(editor, logger, os.Stdout)
+
+	fmt.Println("=== Command Pattern Editor Shell (type \"help\") ===")
+	if err := shell.Run(os.Stdin); err != nil {
+		fmt.Printf("error: %v\n", err)
+	}
+}
+
+// runDemo runs a fixed editing script.
+func runDemo(logger domain.Logger) {
 	// Initialize the Invoker (which holds the Receiver internally)
 	editor := usecase.NewEditor(logger)
 
@@ -17,39 +46,150 @@ func main() {
 
 	// 1. Type "Hello"
 	cmd1 := adapter.NewInsertCommand("Hello", logger)
-	editor.Execute(cmd1)
+	check(editor.Execute(cmd1))
 	printStatus(editor)
 
 	// 2. Type " World"
 	cmd2 := adapter.NewInsertCommand(" World", logger)
-	editor.Execute(cmd2)
+	check(editor.Execute(cmd2))
 	printStatus(editor)
 
 	// 3. Type "!!!"
 	cmd3 := adapter.NewInsertCommand("!!!", logger)
-	editor.Execute(cmd3)
+	check(editor.Execute(cmd3))
 	printStatus(editor)
 
 	// 4. Delete last 3 chars ("!!!")
 	fmt.Println("\n--- Oops, too excited. Deleting '!!!' ---")
 	cmdDelete := adapter.NewDeleteCommand(3, logger)
-	editor.Execute(cmdDelete)
+	check(editor.Execute(cmdDelete))
 	printStatus(editor)
 
 	// 5. Undo the delete (Bring back "!!!")
 	fmt.Println("\n--- Wait, I wanted them back! (Undo) ---")
-	editor.Undo()
+	check(editor.Undo())
 	printStatus(editor)
 
 	// 6. Undo the "!!!" insert
 	fmt.Println("\n--- Undo again (Remove '!!!') ---")
-	editor.Undo()
+	check(editor.Undo())
 	printStatus(editor)
 
 	// 7. Undo the " World" insert
 	fmt.Println("\n--- Undo again (Remove ' World') ---")
-	editor.Undo()
+	check(editor.Undo())
+	printStatus(editor)
+
+	// 8. Redo the " World" insert
+	fmt.Println("\n--- Changed my mind again (Redo) ---")
+	check(editor.Redo())
+	printStatus(editor)
+
+	// 9. Select "World" and replace it as one transaction (positions are rune offsets)
+	fmt.Println("\n--- Select 'World' and replace it with '世界' ---")
+	editor.BeginGroup("replace word")
+	check(editor.Execute(adapter.NewSelectCommand(6, 11, logger)))
+	check(editor.Execute(adapter.NewReplaceSelectionCommand("世界", logger)))
+	editor.EndGroup()
+	printStatus(editor)
+
+	// 10. Insert in the middle of multi-byte text
+	check(editor.Execute(adapter.NewInsertAtCommand(7, "の", logger)))
+	printStatus(editor)
+
+	// 11. Undo the insert, then the whole replace transaction
+	fmt.Println("\n--- Undo the edits ---")
+	check(editor.Undo())
+	check(editor.Undo())
+	printStatus(editor)
+
+	// 12. Invalid commands are rejected and never reach the history
+	fmt.Println("\n--- Try to delete a negative number of chars ---")
+	check(editor.Execute(adapter.NewDeleteCommand(-1, logger)))
 	printStatus(editor)
+
+	// 13. Coalesce keystrokes into one undo step
+	fmt.Println("\n--- Typing 'h', 'e', 'l', 'l', 'o' with merging ---")
+	typist := usecase.NewEditor(logger, usecase.WithMerging(time.Second))
+	for _, key := range []string{"h", "e", "l", "l", "o"} {
+		check(typist.Execute(adapter.NewInsertCommand(key, logger)))
+	}
+	printStatus(typist)
+	check(typist.Undo())
+	printStatus(typist)
+
+	// 14. Journal every operation and recover after a "crash"
+	fmt.Println("\n--- Journal and crash recovery ---")
+	demoJournal(logger)
+
+	// 15. Two clients edit one buffer concurrently, each with its own undo history
+	fmt.Println("\n--- Shared buffer with two clients ---")
+	demoShared(logger)
+}
+
+// demoShared lets two goroutines type into the same buffer.
+func demoShared(logger domain.Logger) {
+	shared := usecase.NewSharedEditor(logger)
+	alice := shared.Client("alice")
+	bob := shared.Client("bob")
+
+	check(alice.Execute(adapter.NewInsertCommand("[alice]", logger)))
+	check(bob.Execute(adapter.NewMoveCursorCommand(0, logger)))
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		check(alice.Execute(adapter.NewReplaceSelectionCommand(" typing", logger)))
+	}()
+	go func() {
+		defer wg.Done()
+		check(bob.Execute(adapter.NewReplaceSelectionCommand("[bob] ", logger)))
+	}()
+	wg.Wait()
+	fmt.Printf("Shared Buffer: \"%s\"\n", shared.GetContent())
+
+	// Bob's undo only reverts Bob's edit
+	check(bob.Undo())
+	fmt.Printf("Shared Buffer: \"%s\"\n", shared.GetContent())
+}
+
+// demoJournal writes operations to a JSON Lines journal, then rebuilds a
+// fresh editor from it as if the process had restarted.
+func demoJournal(logger domain.Logger) {
+	dir, err := os.MkdirTemp("", "command-example")
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	journal := adapter.NewFileJournal(filepath.Join(dir, "editor.jsonl"))
+	registry := adapter.NewDefaultRegistry(logger)
+
+	editor := usecase.NewEditor(logger, usecase.WithJournal(journal, registry))
+	check(editor.Execute(adapter.NewInsertCommand("Journaled", logger)))
+	check(editor.Execute(adapter.NewInsertCommand(" text", logger)))
+	check(editor.Undo())
+	printStatus(editor)
+
+	// The editor is gone; only the journal survives
+	recovered, err := usecase.Recover(logger, adapter.NewFileJournal(filepath.Join(dir, "editor.jsonl")), registry)
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
+	}
+	fmt.Print("Recovered -> ")
+	printStatus(recovered)
+	check(recovered.Redo())
+	printStatus(recovered)
+}
+
+// check reports a failed command without stopping the demo.
+func check(err error) {
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+	}
 }
 
 func printStatus(e *usecase.Editor) {
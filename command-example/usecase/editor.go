@@ -1,48 +1,315 @@
 package usecase
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"command-example/domain"
 )
 
 // Editor acts as the Invoker.
-// It holds the Receiver (Buffer) and the History (Stack).
+// It holds the Receiver (Buffer) and the History (undo/redo stacks).
 type Editor struct {
-	buffer *domain.Buffer
-	stack  []domain.Command
-	logger domain.Logger
+	buffer     *domain.Buffer
+	history    *History
+	group      *domain.MacroCommand // Open transaction, if any
+	groupDepth int
+	journal    domain.Journal // Optional write-ahead log of operations
+	codec      domain.CommandCodec
+	merging    bool // Coalesce consecutive Mergeable commands
+	mergeGap   time.Duration
+	lastExec   time.Time
+	boundary   bool // Set when the next command must start a new history entry
+	now        func() time.Time
+	logger     domain.Logger
+}
+
+// EditorOption configures an Editor.
+type EditorOption func(*Editor)
+
+// WithMaxHistory limits the number of undoable commands.
+// The oldest commands are evicted once the limit is exceeded.
+func WithMaxHistory(n int) EditorOption {
+	return func(e *Editor) {
+		e.history = NewHistory(n)
+	}
+}
+
+// WithJournal records every operation to the journal, using codec to serialize
+// commands. Commands that cannot be encoded are rejected before they run.
+func WithJournal(journal domain.Journal, codec domain.CommandCodec) EditorOption {
+	return func(e *Editor) {
+		e.journal = journal
+		e.codec = codec
+	}
+}
+
+// WithMerging coalesces consecutive compatible commands (e.g. keystrokes) into
+// one history entry. Commands merge while they follow each other within window;
+// a window of 0 merges until a boundary (undo, redo, a group, MarkBoundary or a
+// command that cannot merge).
+func WithMerging(window time.Duration) EditorOption {
+	return func(e *Editor) {
+		e.merging = true
+		e.mergeGap = window
+	}
+}
+
+// WithClock replaces the clock used for the merge window.
+func WithClock(now func() time.Time) EditorOption {
+	return func(e *Editor) {
+		e.now = now
+	}
+}
+
+// NewEditor builds an editor with an empty buffer and unlimited history.
+func NewEditor(logger domain.Logger, opts ...EditorOption) *Editor {
+	e := &Editor{
+		buffer:  domain.NewBuffer(),
+		history: NewHistory(0),
+		now:     time.Now,
+		logger:  logger,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
 }
 
-// NewEditor builds an editor with an empty buffer.
-func NewEditor(logger domain.Logger) *Editor {
-	return &Editor{
-		buffer: domain.NewBuffer(),
-		logger: logger,
+// Execute performs a command and pushes the applied command to the history.
+// Executing a new command discards anything that could have been redone.
+// Inside a group, the command is recorded in the group instead.
+// A command that fails is not recorded. Inside a group, a failure also rolls
+// back the whole transaction (see AbortGroup).
+func (e *Editor) Execute(c domain.Command) error {
+	err := e.execute(c, e.mergeAllowed())
+	if err != nil && e.group != nil {
+		return errors.Join(err, e.AbortGroup())
 	}
+	return err
 }
 
-// Execute performs a command and pushes it to the stack.
-func (e *Editor) Execute(c domain.Command) {
-	c.Do(e.buffer)
-	e.stack = append(e.stack, c)
+// MarkBoundary ends the current merge run, so the next command starts a new undo step.
+func (e *Editor) MarkBoundary() {
+	e.boundary = true
+}
+
+func (e *Editor) execute(c domain.Command, allowMerge bool) error {
+	var rec domain.CommandRecord
+	if e.journal != nil {
+		var err error
+		if rec, err = e.codec.Encode(c); err != nil {
+			return fmt.Errorf("execute: %w", err)
+		}
+	}
+
+	applied, err := c.Do(e.buffer)
+	if err != nil {
+		return fmt.Errorf("execute: %w", err)
+	}
+
+	var merged domain.Command
+	if allowMerge {
+		merged = e.tryMerge(applied)
+	}
+	if err := e.record(domain.JournalEntry{Op: domain.OpExecute, Command: &rec, Merge: merged != nil}); err != nil {
+		// Keep the buffer in step with the journal
+		return errors.Join(fmt.Errorf("execute: %w", err), applied.Undo(e.buffer))
+	}
+
+	e.lastExec = e.now()
+	e.boundary = false
+	switch {
+	case e.group != nil:
+		e.group.Add(applied)
+	case merged != nil:
+		e.history.mergeLatest(merged)
+	default:
+		e.history.Push(applied)
+	}
+	return nil
+}
+
+// mergeAllowed reports whether the next command may join the latest history entry.
+func (e *Editor) mergeAllowed() bool {
+	if !e.merging || e.boundary || e.group != nil {
+		return false
+	}
+	return e.mergeGap == 0 || e.now().Sub(e.lastExec) <= e.mergeGap
+}
+
+// tryMerge returns the latest history entry combined with applied, or nil.
+func (e *Editor) tryMerge(applied domain.Command) domain.Command {
+	latest, ok := e.history.PeekUndo()
+	if !ok {
+		return nil
+	}
+	m, ok := latest.(domain.Mergeable)
+	if !ok {
+		return nil
+	}
+	merged, ok := m.MergeWith(applied)
+	if !ok {
+		return nil
+	}
+	return merged
+}
+
+// BeginGroup starts a transaction. Commands executed until the matching
+// EndGroup are recorded as a single MacroCommand, so one Undo reverts them all.
+// Groups may be nested; only the outermost EndGroup commits.
+func (e *Editor) BeginGroup(name string) error {
+	if err := e.record(domain.JournalEntry{Op: domain.OpBeginGroup, Group: name}); err != nil {
+		return fmt.Errorf("begin group: %w", err)
+	}
+	e.boundary = true
+	if e.group == nil {
+		e.group = domain.NewMacroCommand(name)
+	}
+	e.groupDepth++
+	return nil
+}
+
+// EndGroup closes the current transaction and pushes it to the history.
+// Empty groups are discarded.
+func (e *Editor) EndGroup() error {
+	if e.group == nil {
+		e.logger.Log("[WARN] No group to end.")
+		return nil
+	}
+	if err := e.record(domain.JournalEntry{Op: domain.OpEndGroup}); err != nil {
+		return fmt.Errorf("end group: %w", err)
+	}
+	e.boundary = true
+
+	e.groupDepth--
+	if e.groupDepth > 0 {
+		return nil
+	}
+
+	macro := e.group
+	e.group = nil
+	if macro.Len() > 0 {
+		e.history.Push(macro)
+	}
+	return nil
+}
+
+// AbortGroup closes the open transaction without committing it: the commands
+// executed in it are undone in reverse order and nothing is pushed to the
+// history. Nested groups are aborted as a whole.
+// If a command cannot be undone, the ones already undone are applied again
+// and the group stays open.
+func (e *Editor) AbortGroup() error {
+	if e.group == nil {
+		e.logger.Log("[WARN] No group to abort.")
+		return nil
+	}
+	if err := e.group.Undo(e.buffer); err != nil {
+		return fmt.Errorf("abort group: %w", err)
+	}
+	if err := e.record(domain.JournalEntry{Op: domain.OpAbortGroup}); err != nil {
+		// Keep the buffer in step with the journal
+		redone, redoErr := e.group.Do(e.buffer)
+		if redoErr == nil {
+			e.group = redone.(*domain.MacroCommand)
+		}
+		return errors.Join(fmt.Errorf("abort group: %w", err), redoErr)
+	}
+
+	e.logger.Log(fmt.Sprintf("[WARN] Group '%s' rolled back.", e.group.Name))
+	e.group = nil
+	e.groupDepth = 0
+	e.boundary = true
+	return nil
+}
+
+// InGroup reports whether a transaction is open.
+func (e *Editor) InGroup() bool {
+	return e.group != nil
 }
 
 // Undo pops the last command and reverses it.
-func (e *Editor) Undo() {
-	if len(e.stack) == 0 {
-		e.logger.Log("[WARN] Nothing to undo.")
-		return
+// If the command cannot be undone, it stays on the history.
+func (e *Editor) Undo() error {
+	if e.group != nil {
+		e.logger.Log("[WARN] Cannot undo while a group is open.")
+		return nil
 	}
 
-	// Pop
-	lastIndex := len(e.stack) - 1
-	cmd := e.stack[lastIndex]
-	e.stack = e.stack[:lastIndex]
+	cmd, ok := e.history.PeekUndo()
+	if !ok {
+		e.logger.Log("[WARN] Nothing to undo.")
+		return nil
+	}
 
 	// Execute Undo logic
-	cmd.Undo(e.buffer)
+	if err := cmd.Undo(e.buffer); err != nil {
+		return fmt.Errorf("undo: %w", err)
+	}
+	if err := e.record(domain.JournalEntry{Op: domain.OpUndo}); err != nil {
+		// Keep the buffer in step with the journal
+		redone, redoErr := cmd.Do(e.buffer)
+		if redoErr == nil {
+			e.history.replaceLatest(redone)
+		}
+		return errors.Join(fmt.Errorf("undo: %w", err), redoErr)
+	}
+	e.history.Undo()
+	e.boundary = true
+	return nil
+}
+
+// Redo re-applies the most recently undone command.
+// If the command cannot be redone, it stays on the redo stack.
+func (e *Editor) Redo() error {
+	if e.group != nil {
+		e.logger.Log("[WARN] Cannot redo while a group is open.")
+		return nil
+	}
+
+	cmd, ok := e.history.PeekRedo()
+	if !ok {
+		e.logger.Log("[WARN] Nothing to redo.")
+		return nil
+	}
+
+	applied, err := cmd.Do(e.buffer)
+	if err != nil {
+		return fmt.Errorf("redo: %w", err)
+	}
+	if err := e.record(domain.JournalEntry{Op: domain.OpRedo}); err != nil {
+		// Keep the buffer in step with the journal
+		return errors.Join(fmt.Errorf("redo: %w", err), applied.Undo(e.buffer))
+	}
+	e.history.Redo()
+	e.history.replaceLatest(applied)
+	e.boundary = true
+	return nil
+}
+
+// CanUndo reports whether Undo would have an effect.
+func (e *Editor) CanUndo() bool {
+	return e.history.CanUndo()
+}
+
+// CanRedo reports whether Redo would have an effect.
+func (e *Editor) CanRedo() bool {
+	return e.history.CanRedo()
+}
+
+// Stacks returns copies of the undo and redo stacks, oldest first.
+func (e *Editor) Stacks() (undo, redo []domain.Command) {
+	return e.history.Entries()
 }
 
 // GetContent returns the current buffer content.
 func (e *Editor) GetContent() string {
 	return e.buffer.Content
 }
+
+// Cursor returns the cursor position in runes.
+func (e *Editor) Cursor() int {
+	return e.buffer.Cursor
+}
@@ -1,8 +1,10 @@
 package usecase_test
 
 import (
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"command-example/domain"
 	"command-example/usecase"
@@ -21,16 +23,18 @@ type MockCommand struct {
 	Undone   bool
 }
 
-func (m *MockCommand) Do(b *domain.Buffer) {
+func (m *MockCommand) Do(b *domain.Buffer) (domain.Command, error) {
 	m.Executed = true
 	b.Content += "X"
+	return m, nil
 }
 
-func (m *MockCommand) Undo(b *domain.Buffer) {
+func (m *MockCommand) Undo(b *domain.Buffer) error {
 	m.Undone = true
 	if len(b.Content) > 0 {
 		b.Content = b.Content[:len(b.Content)-1]
 	}
+	return nil
 }
 
 func TestEditor_Undo(t *testing.T) {
@@ -60,3 +64,417 @@ func TestEditor_Undo(t *testing.T) {
 		t.Error("Expected warning log on empty undo")
 	}
 }
+
+// AppendCommand appends a fixed text so tests can tell commands apart.
+type AppendCommand struct {
+	Text string
+}
+
+func (a *AppendCommand) Do(b *domain.Buffer) (domain.Command, error) {
+	b.Content += a.Text
+	return a, nil
+}
+
+func (a *AppendCommand) Undo(b *domain.Buffer) error {
+	b.Content = strings.TrimSuffix(b.Content, a.Text)
+	return nil
+}
+
+func (a *AppendCommand) Record() (domain.CommandRecord, error) {
+	return domain.NewCommandRecord("append", a.Text)
+}
+
+// FailingCommand fails on Do or Undo, as configured.
+type FailingCommand struct {
+	DoErr   error
+	UndoErr error
+}
+
+func (f *FailingCommand) Do(b *domain.Buffer) (domain.Command, error) {
+	if f.DoErr != nil {
+		return nil, f.DoErr
+	}
+	return f, nil
+}
+
+func (f *FailingCommand) Undo(b *domain.Buffer) error {
+	return f.UndoErr
+}
+
+func TestEditor_Redo(t *testing.T) {
+	logger := &MockLogger{}
+	editor := usecase.NewEditor(logger)
+
+	editor.Execute(&AppendCommand{Text: "A"})
+	editor.Execute(&AppendCommand{Text: "B"})
+
+	editor.Undo()
+	editor.Undo()
+	if editor.GetContent() != "" {
+		t.Fatalf("expected empty buffer after undo, got %q", editor.GetContent())
+	}
+
+	editor.Redo()
+	if editor.GetContent() != "A" {
+		t.Errorf("expected %q after first redo, got %q", "A", editor.GetContent())
+	}
+	editor.Redo()
+	if editor.GetContent() != "AB" {
+		t.Errorf("expected %q after second redo, got %q", "AB", editor.GetContent())
+	}
+
+	// Redo on empty stack
+	editor.Redo()
+	if len(logger.Logs) == 0 || !strings.Contains(logger.Logs[0], "Nothing to redo") {
+		t.Error("Expected warning log on empty redo")
+	}
+}
+
+func TestEditor_ExecuteInvalidatesRedo(t *testing.T) {
+	editor := usecase.NewEditor(&MockLogger{})
+
+	editor.Execute(&AppendCommand{Text: "A"})
+	editor.Execute(&AppendCommand{Text: "B"})
+	editor.Undo()
+	if !editor.CanRedo() {
+		t.Fatal("expected CanRedo after undo")
+	}
+
+	editor.Execute(&AppendCommand{Text: "C"})
+	if editor.CanRedo() {
+		t.Error("expected redo branch to be discarded after a new command")
+	}
+	if editor.GetContent() != "AC" {
+		t.Errorf("expected %q, got %q", "AC", editor.GetContent())
+	}
+}
+
+func TestEditor_MaxHistory(t *testing.T) {
+	editor := usecase.NewEditor(&MockLogger{}, usecase.WithMaxHistory(2))
+
+	editor.Execute(&AppendCommand{Text: "A"})
+	editor.Execute(&AppendCommand{Text: "B"})
+	editor.Execute(&AppendCommand{Text: "C"})
+
+	editor.Undo()
+	editor.Undo()
+	if editor.CanUndo() {
+		t.Error("expected oldest command to be evicted")
+	}
+	if editor.GetContent() != "A" {
+		t.Errorf("expected %q, got %q", "A", editor.GetContent())
+	}
+}
+
+func TestEditor_CanUndoCanRedo(t *testing.T) {
+	editor := usecase.NewEditor(&MockLogger{})
+	if editor.CanUndo() || editor.CanRedo() {
+		t.Fatal("new editor should have nothing to undo or redo")
+	}
+
+	editor.Execute(&AppendCommand{Text: "A"})
+	if !editor.CanUndo() || editor.CanRedo() {
+		t.Error("expected CanUndo only after execute")
+	}
+
+	editor.Undo()
+	if editor.CanUndo() || !editor.CanRedo() {
+		t.Error("expected CanRedo only after undo")
+	}
+}
+
+func TestEditor_GroupUndoesAtomically(t *testing.T) {
+	editor := usecase.NewEditor(&MockLogger{})
+	editor.Execute(&AppendCommand{Text: "Hello"})
+
+	editor.BeginGroup("replace word")
+	editor.Execute(&AppendCommand{Text: " big"})
+	editor.Execute(&AppendCommand{Text: " world"})
+	if !editor.InGroup() {
+		t.Fatal("expected an open group")
+	}
+	editor.EndGroup()
+
+	if editor.GetContent() != "Hello big world" {
+		t.Fatalf("unexpected content: %q", editor.GetContent())
+	}
+
+	editor.Undo()
+	if editor.GetContent() != "Hello" {
+		t.Errorf("expected the whole group to be undone, got %q", editor.GetContent())
+	}
+
+	editor.Redo()
+	if editor.GetContent() != "Hello big world" {
+		t.Errorf("expected the whole group to be redone, got %q", editor.GetContent())
+	}
+}
+
+func TestEditor_NestedGroups(t *testing.T) {
+	editor := usecase.NewEditor(&MockLogger{})
+
+	editor.BeginGroup("outer")
+	editor.Execute(&AppendCommand{Text: "A"})
+	editor.BeginGroup("inner")
+	editor.Execute(&AppendCommand{Text: "B"})
+	editor.EndGroup()
+	if !editor.InGroup() {
+		t.Fatal("inner EndGroup should not close the outer group")
+	}
+	editor.Execute(&AppendCommand{Text: "C"})
+	editor.EndGroup()
+
+	editor.Undo()
+	if editor.GetContent() != "" || editor.CanUndo() {
+		t.Errorf("expected a single undo step, got %q", editor.GetContent())
+	}
+}
+
+func TestEditor_GroupWarnings(t *testing.T) {
+	logger := &MockLogger{}
+	editor := usecase.NewEditor(logger)
+
+	editor.EndGroup()
+	editor.BeginGroup("empty")
+	editor.Undo()
+	editor.EndGroup()
+
+	if editor.CanUndo() {
+		t.Error("empty group should not be pushed to the history")
+	}
+	if len(logger.Logs) != 2 ||
+		!strings.Contains(logger.Logs[0], "No group to end") ||
+		!strings.Contains(logger.Logs[1], "Cannot undo while a group is open") {
+		t.Errorf("unexpected logs: %v", logger.Logs)
+	}
+}
+
+func TestEditor_GroupRollsBackOnFailure(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name   string
+		nested bool
+	}{
+		{"Single", false},
+		{"Nested", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			editor := usecase.NewEditor(&MockLogger{})
+			editor.Execute(&AppendCommand{Text: "Hello"})
+
+			editor.BeginGroup("outer")
+			editor.Execute(&AppendCommand{Text: " big"})
+			if tt.nested {
+				editor.BeginGroup("inner")
+			}
+			editor.Execute(&AppendCommand{Text: " world"})
+
+			err := editor.Execute(&FailingCommand{DoErr: errBoom})
+			if !errors.Is(err, errBoom) {
+				t.Fatalf("expected error to propagate, got %v", err)
+			}
+			if editor.GetContent() != "Hello" {
+				t.Errorf("expected the group's commands to be undone, got %q", editor.GetContent())
+			}
+			if editor.InGroup() {
+				t.Error("expected the failed group to be closed")
+			}
+
+			// Closing it afterwards commits nothing
+			editor.EndGroup()
+			editor.Undo()
+			if editor.GetContent() != "" || editor.CanUndo() {
+				t.Errorf("expected only the command before the group on the history, got %q", editor.GetContent())
+			}
+		})
+	}
+}
+
+func TestEditor_AbortGroup(t *testing.T) {
+	logger := &MockLogger{}
+	editor := usecase.NewEditor(logger)
+
+	editor.AbortGroup()
+	if len(logger.Logs) != 1 || !strings.Contains(logger.Logs[0], "No group to abort") {
+		t.Errorf("unexpected logs: %v", logger.Logs)
+	}
+
+	editor.BeginGroup("draft")
+	editor.Execute(&AppendCommand{Text: "A"})
+	editor.Execute(&AppendCommand{Text: "B"})
+	if err := editor.AbortGroup(); err != nil {
+		t.Fatalf("AbortGroup failed: %v", err)
+	}
+	if editor.GetContent() != "" || editor.InGroup() || editor.CanUndo() {
+		t.Errorf("expected the group to be discarded, got %q", editor.GetContent())
+	}
+}
+
+func TestEditor_AbortGroupKeepsGroupWhenUndoFails(t *testing.T) {
+	editor := usecase.NewEditor(&MockLogger{})
+	errBoom := errors.New("boom")
+
+	editor.BeginGroup("stuck")
+	editor.Execute(&FailingCommand{UndoErr: errBoom})
+	editor.Execute(&AppendCommand{Text: "A"})
+
+	if err := editor.AbortGroup(); !errors.Is(err, errBoom) {
+		t.Fatalf("expected error to propagate, got %v", err)
+	}
+	// "A" was undone and applied again
+	if editor.GetContent() != "A" || !editor.InGroup() {
+		t.Errorf("expected the group to stay open and applied, got %q", editor.GetContent())
+	}
+}
+
+func TestEditor_FailedExecuteIsNotRecorded(t *testing.T) {
+	editor := usecase.NewEditor(&MockLogger{})
+	errBoom := errors.New("boom")
+
+	err := editor.Execute(&FailingCommand{DoErr: errBoom})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("expected error to propagate, got %v", err)
+	}
+	if editor.CanUndo() {
+		t.Error("failed command should not be pushed to the history")
+	}
+}
+
+func TestEditor_FailedUndoStaysOnHistory(t *testing.T) {
+	editor := usecase.NewEditor(&MockLogger{})
+	errBoom := errors.New("boom")
+
+	if err := editor.Execute(&FailingCommand{UndoErr: errBoom}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := editor.Undo()
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("expected error to propagate, got %v", err)
+	}
+	if !editor.CanUndo() || editor.CanRedo() {
+		t.Error("failed undo should leave the command on the undo stack")
+	}
+}
+
+func TestEditor_FailedRedoStaysOnRedoStack(t *testing.T) {
+	editor := usecase.NewEditor(&MockLogger{})
+	cmd := &FailingCommand{}
+	errBoom := errors.New("boom")
+
+	if err := editor.Execute(cmd); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := editor.Undo(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cmd.DoErr = errBoom
+	err := editor.Redo()
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("expected error to propagate, got %v", err)
+	}
+	if !editor.CanRedo() || editor.CanUndo() {
+		t.Error("failed redo should leave the command on the redo stack")
+	}
+}
+
+// MergeCommand appends text and merges with any following MergeCommand.
+type MergeCommand struct {
+	Text string
+}
+
+func (m MergeCommand) Do(b *domain.Buffer) (domain.Command, error) {
+	b.Content += m.Text
+	return m, nil
+}
+
+func (m MergeCommand) Undo(b *domain.Buffer) error {
+	b.Content = strings.TrimSuffix(b.Content, m.Text)
+	return nil
+}
+
+func (m MergeCommand) MergeWith(next domain.Command) (domain.Command, bool) {
+	n, ok := next.(MergeCommand)
+	if !ok {
+		return nil, false
+	}
+	return MergeCommand{Text: m.Text + n.Text}, true
+}
+
+// fakeClock advances only when told to.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.t }
+
+func typeText(editor *usecase.Editor, text string) {
+	for _, r := range text {
+		editor.Execute(MergeCommand{Text: string(r)})
+	}
+}
+
+func TestEditor_MergeTyping(t *testing.T) {
+	editor := usecase.NewEditor(&MockLogger{}, usecase.WithMerging(0))
+
+	typeText(editor, "hello")
+	editor.Undo()
+	if editor.GetContent() != "" || editor.CanUndo() {
+		t.Errorf("expected one undo step, got %q", editor.GetContent())
+	}
+}
+
+func TestEditor_MergeWindow(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	editor := usecase.NewEditor(&MockLogger{},
+		usecase.WithMerging(time.Second),
+		usecase.WithClock(clock.Now),
+	)
+
+	typeText(editor, "ab")
+	clock.t = clock.t.Add(2 * time.Second)
+	typeText(editor, "cd")
+
+	editor.Undo()
+	if editor.GetContent() != "ab" {
+		t.Errorf("expected the pause to split the run, got %q", editor.GetContent())
+	}
+}
+
+func TestEditor_MergeBoundaries(t *testing.T) {
+	editor := usecase.NewEditor(&MockLogger{}, usecase.WithMerging(0))
+
+	typeText(editor, "ab")
+	editor.MarkBoundary()
+	typeText(editor, "cd")
+	editor.Execute(&AppendCommand{Text: "|"})
+	typeText(editor, "ef")
+
+	editor.Undo()
+	if editor.GetContent() != "abcd|" {
+		t.Errorf("expected a non-mergeable command to end the run, got %q", editor.GetContent())
+	}
+
+	// Undo is a boundary as well
+	typeText(editor, "gh")
+	editor.Undo()
+	editor.Undo()
+	editor.Undo()
+	if editor.GetContent() != "ab" {
+		t.Errorf("expected MarkBoundary to split the run, got %q", editor.GetContent())
+	}
+}
+
+func TestEditor_MergingDisabledByDefault(t *testing.T) {
+	editor := usecase.NewEditor(&MockLogger{})
+
+	typeText(editor, "ab")
+	editor.Undo()
+	if editor.GetContent() != "a" {
+		t.Errorf("expected no merging without WithMerging, got %q", editor.GetContent())
+	}
+}
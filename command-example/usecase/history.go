@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"command-example/domain"
+)
+
+// History manages the undo and redo stacks of executed commands.
+// When maxDepth is positive, the oldest undo entries are evicted once the limit is exceeded.
+type History struct {
+	undo     []domain.Command
+	redo     []domain.Command
+	maxDepth int
+}
+
+// NewHistory creates a history. A maxDepth of 0 (or less) means unlimited.
+func NewHistory(maxDepth int) *History {
+	return &History{maxDepth: maxDepth}
+}
+
+// Push records a newly executed command.
+// Any redo branch is discarded, because it no longer follows from the current state.
+func (h *History) Push(c domain.Command) {
+	h.undo = append(h.undo, c)
+	h.redo = nil
+
+	if h.maxDepth > 0 && len(h.undo) > h.maxDepth {
+		// Evict oldest entries
+		h.undo = h.undo[len(h.undo)-h.maxDepth:]
+	}
+}
+
+// Undo pops the latest command and moves it to the redo stack.
+func (h *History) Undo() (domain.Command, bool) {
+	if len(h.undo) == 0 {
+		return nil, false
+	}
+	lastIndex := len(h.undo) - 1
+	cmd := h.undo[lastIndex]
+	h.undo = h.undo[:lastIndex]
+	h.redo = append(h.redo, cmd)
+	return cmd, true
+}
+
+// Redo pops the latest undone command and moves it back to the undo stack.
+func (h *History) Redo() (domain.Command, bool) {
+	if len(h.redo) == 0 {
+		return nil, false
+	}
+	lastIndex := len(h.redo) - 1
+	cmd := h.redo[lastIndex]
+	h.redo = h.redo[:lastIndex]
+	h.undo = append(h.undo, cmd)
+	return cmd, true
+}
+
+// PeekUndo returns the command Undo would pop, without removing it.
+func (h *History) PeekUndo() (domain.Command, bool) {
+	if len(h.undo) == 0 {
+		return nil, false
+	}
+	return h.undo[len(h.undo)-1], true
+}
+
+// PeekRedo returns the command Redo would pop, without removing it.
+func (h *History) PeekRedo() (domain.Command, bool) {
+	if len(h.redo) == 0 {
+		return nil, false
+	}
+	return h.redo[len(h.redo)-1], true
+}
+
+// replaceLatest swaps the top of the undo stack, e.g. for the freshly applied
+// copy returned by a redone command.
+func (h *History) replaceLatest(c domain.Command) {
+	if len(h.undo) > 0 {
+		h.undo[len(h.undo)-1] = c
+	}
+}
+
+// mergeLatest replaces the top of the undo stack with a coalesced command.
+// Like Push, it discards the redo branch.
+func (h *History) mergeLatest(c domain.Command) {
+	h.replaceLatest(c)
+	h.redo = nil
+}
+
+// Clear drops both stacks.
+func (h *History) Clear() {
+	h.undo = nil
+	h.redo = nil
+}
+
+// CanUndo reports whether there is a command to undo.
+func (h *History) CanUndo() bool {
+	return len(h.undo) > 0
+}
+
+// CanRedo reports whether there is a command to redo.
+func (h *History) CanRedo() bool {
+	return len(h.redo) > 0
+}
+
+// Entries returns copies of the undo and redo stacks, oldest first.
+func (h *History) Entries() (undo, redo []domain.Command) {
+	return append([]domain.Command(nil), h.undo...), append([]domain.Command(nil), h.redo...)
+}
+
+// UndoDepth returns the number of commands that can be undone.
+func (h *History) UndoDepth() int {
+	return len(h.undo)
+}
+
+// RedoDepth returns the number of commands that can be redone.
+func (h *History) RedoDepth() int {
+	return len(h.redo)
+}
@@ -0,0 +1,68 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"command-example/usecase"
+)
+
+func TestHistory_PushUndoRedo(t *testing.T) {
+	h := usecase.NewHistory(0)
+	a := &AppendCommand{Text: "A"}
+	b := &AppendCommand{Text: "B"}
+
+	h.Push(a)
+	h.Push(b)
+	if h.UndoDepth() != 2 || h.RedoDepth() != 0 {
+		t.Fatalf("unexpected depths: undo=%d redo=%d", h.UndoDepth(), h.RedoDepth())
+	}
+
+	cmd, ok := h.Undo()
+	if !ok || cmd != b {
+		t.Fatalf("expected to undo B, got %v (ok=%v)", cmd, ok)
+	}
+	cmd, ok = h.Redo()
+	if !ok || cmd != b {
+		t.Fatalf("expected to redo B, got %v (ok=%v)", cmd, ok)
+	}
+	if _, ok := h.Redo(); ok {
+		t.Error("expected empty redo stack")
+	}
+}
+
+func TestHistory_MaxDepthEvictsOldest(t *testing.T) {
+	h := usecase.NewHistory(2)
+	a := &AppendCommand{Text: "A"}
+	b := &AppendCommand{Text: "B"}
+	c := &AppendCommand{Text: "C"}
+
+	h.Push(a)
+	h.Push(b)
+	h.Push(c)
+	if h.UndoDepth() != 2 {
+		t.Fatalf("expected depth 2, got %d", h.UndoDepth())
+	}
+
+	first, _ := h.Undo()
+	second, _ := h.Undo()
+	if first != c || second != b {
+		t.Errorf("expected C then B, got %v then %v", first, second)
+	}
+	if h.CanUndo() {
+		t.Error("expected A to be evicted")
+	}
+}
+
+func TestHistory_PushClearsRedo(t *testing.T) {
+	h := usecase.NewHistory(0)
+	h.Push(&AppendCommand{Text: "A"})
+	h.Undo()
+	if !h.CanRedo() {
+		t.Fatal("expected CanRedo after undo")
+	}
+
+	h.Push(&AppendCommand{Text: "B"})
+	if h.CanRedo() {
+		t.Error("expected redo stack to be cleared by Push")
+	}
+}
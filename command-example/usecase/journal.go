@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"command-example/domain"
+)
+
+var (
+	// ErrNoJournal is returned by Compact when the editor was built without WithJournal.
+	ErrNoJournal = errors.New("no journal configured")
+	// ErrGroupOpen is returned by Compact while a transaction is open.
+	ErrGroupOpen = errors.New("group is open")
+)
+
+// Recover rebuilds an editor after a restart: it restores the latest snapshot
+// and replays every operation recorded after it, which also rebuilds the
+// undo/redo history. The returned editor keeps journaling to the same journal.
+func Recover(logger domain.Logger, journal domain.Journal, codec domain.CommandCodec, opts ...EditorOption) (*Editor, error) {
+	snap, entries, err := journal.Load()
+	if err != nil {
+		return nil, fmt.Errorf("recover: %w", err)
+	}
+
+	e := NewEditor(logger, opts...)
+	e.journal, e.codec = nil, codec // Replay must not append to the journal again
+	snap.Restore(e.buffer)
+
+	for _, entry := range entries {
+		if err := e.replay(entry); err != nil {
+			return nil, fmt.Errorf("recover: entry %d: %w", entry.Seq, err)
+		}
+	}
+
+	e.journal = journal
+	// A crash inside a transaction leaves it open.
+	// Close it (and journal that) so later operations are not replayed inside it.
+	for e.InGroup() {
+		if err := e.EndGroup(); err != nil {
+			return nil, fmt.Errorf("recover: %w", err)
+		}
+	}
+	return e, nil
+}
+
+// Compact writes a snapshot of the buffer and truncates the journal.
+// The snapshot becomes the new baseline, so the undo/redo history is cleared.
+func (e *Editor) Compact() error {
+	if e.journal == nil {
+		return fmt.Errorf("compact: %w", ErrNoJournal)
+	}
+	if e.group != nil {
+		return fmt.Errorf("compact: %w", ErrGroupOpen)
+	}
+	if err := e.journal.Compact(domain.SnapshotOf(e.buffer)); err != nil {
+		return fmt.Errorf("compact: %w", err)
+	}
+	e.history.Clear()
+	return nil
+}
+
+// record appends an entry when journaling is enabled.
+func (e *Editor) record(entry domain.JournalEntry) error {
+	if e.journal == nil {
+		return nil
+	}
+	return e.journal.Append(entry)
+}
+
+// replay applies one journal entry.
+func (e *Editor) replay(entry domain.JournalEntry) error {
+	switch entry.Op {
+	case domain.OpExecute:
+		if entry.Command == nil {
+			return fmt.Errorf("execute entry without command")
+		}
+		c, err := e.codec.Decode(*entry.Command)
+		if err != nil {
+			return err
+		}
+		return e.execute(c, entry.Merge)
+	case domain.OpUndo:
+		return e.Undo()
+	case domain.OpRedo:
+		return e.Redo()
+	case domain.OpBeginGroup:
+		return e.BeginGroup(entry.Group)
+	case domain.OpEndGroup:
+		return e.EndGroup()
+	case domain.OpAbortGroup:
+		return e.AbortGroup()
+	default:
+		return fmt.Errorf("unknown journal op %q", entry.Op)
+	}
+}
@@ -0,0 +1,221 @@
+package usecase_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"command-example/domain"
+	"command-example/usecase"
+)
+
+// MockJournal keeps entries in memory and can be told to fail.
+type MockJournal struct {
+	Snapshot domain.Snapshot
+	Entries  []domain.JournalEntry
+	Err      error
+}
+
+func (m *MockJournal) Append(e domain.JournalEntry) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	e.Seq = int64(len(m.Entries) + 1)
+	m.Entries = append(m.Entries, e)
+	return nil
+}
+
+func (m *MockJournal) Load() (domain.Snapshot, []domain.JournalEntry, error) {
+	return m.Snapshot, m.Entries, nil
+}
+
+func (m *MockJournal) Compact(s domain.Snapshot) error {
+	m.Snapshot = s
+	m.Entries = nil
+	return nil
+}
+
+// appendCodec serializes AppendCommand and MergeCommand.
+type appendCodec struct{}
+
+func (appendCodec) Encode(c domain.Command) (domain.CommandRecord, error) {
+	switch c := c.(type) {
+	case *AppendCommand:
+		return c.Record()
+	case MergeCommand:
+		return domain.NewCommandRecord("merge", c.Text)
+	default:
+		return domain.CommandRecord{}, domain.ErrNotSerializable
+	}
+}
+
+func (appendCodec) Decode(rec domain.CommandRecord) (domain.Command, error) {
+	var text string
+	if err := json.Unmarshal(rec.Args, &text); err != nil {
+		return nil, err
+	}
+	if rec.Type == "merge" {
+		return MergeCommand{Text: text}, nil
+	}
+	return &AppendCommand{Text: text}, nil
+}
+
+func TestRecover_ReplaysOperations(t *testing.T) {
+	journal := &MockJournal{}
+	editor := usecase.NewEditor(&MockLogger{}, usecase.WithJournal(journal, appendCodec{}))
+
+	editor.Execute(&AppendCommand{Text: "Hello"})
+	editor.BeginGroup("words")
+	editor.Execute(&AppendCommand{Text: " big"})
+	editor.Execute(&AppendCommand{Text: " world"})
+	editor.EndGroup()
+	editor.Execute(&AppendCommand{Text: "!"})
+	editor.Undo()
+	editor.Undo()
+	editor.Redo()
+
+	recovered, err := usecase.Recover(&MockLogger{}, journal, appendCodec{})
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if recovered.GetContent() != editor.GetContent() {
+		t.Fatalf("expected %q, got %q", editor.GetContent(), recovered.GetContent())
+	}
+
+	// History is rebuilt too: "!" can be redone, the group undone as one step
+	if err := recovered.Redo(); err != nil || recovered.GetContent() != "Hello big world!" {
+		t.Errorf("unexpected redo result: %q (err=%v)", recovered.GetContent(), err)
+	}
+	recovered.Undo()
+	recovered.Undo()
+	if recovered.GetContent() != "Hello" {
+		t.Errorf("expected %q, got %q", "Hello", recovered.GetContent())
+	}
+}
+
+func TestRecover_ClosesOpenGroup(t *testing.T) {
+	journal := &MockJournal{}
+	editor := usecase.NewEditor(&MockLogger{}, usecase.WithJournal(journal, appendCodec{}))
+	editor.BeginGroup("crash")
+	editor.Execute(&AppendCommand{Text: "A"})
+
+	recovered, err := usecase.Recover(&MockLogger{}, journal, appendCodec{})
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if recovered.InGroup() {
+		t.Error("expected the interrupted group to be closed")
+	}
+	if last := journal.Entries[len(journal.Entries)-1]; last.Op != domain.OpEndGroup {
+		t.Errorf("expected the close to be journaled, got %q", last.Op)
+	}
+}
+
+func TestRecover_ReplaysAbortedGroup(t *testing.T) {
+	journal := &MockJournal{}
+	editor := usecase.NewEditor(&MockLogger{}, usecase.WithJournal(journal, appendCodec{}))
+	editor.Execute(&AppendCommand{Text: "Hello"})
+	editor.BeginGroup("words")
+	editor.Execute(&AppendCommand{Text: " big"})
+	editor.Execute(&FailingCommand{DoErr: errors.New("boom")})
+
+	if last := journal.Entries[len(journal.Entries)-1]; last.Op != domain.OpAbortGroup {
+		t.Fatalf("expected the rollback to be journaled, got %q", last.Op)
+	}
+	recovered, err := usecase.Recover(&MockLogger{}, journal, appendCodec{})
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if recovered.GetContent() != "Hello" || recovered.InGroup() {
+		t.Errorf("expected the group to stay rolled back, got %q", recovered.GetContent())
+	}
+}
+
+func TestEditor_Compact(t *testing.T) {
+	journal := &MockJournal{}
+	editor := usecase.NewEditor(&MockLogger{}, usecase.WithJournal(journal, appendCodec{}))
+	editor.Execute(&AppendCommand{Text: "Hello"})
+
+	if err := editor.Compact(); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	if editor.CanUndo() {
+		t.Error("expected history to be cleared by compaction")
+	}
+	editor.Execute(&AppendCommand{Text: "!"})
+
+	recovered, err := usecase.Recover(&MockLogger{}, journal, appendCodec{})
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if recovered.GetContent() != "Hello!" {
+		t.Errorf("expected %q, got %q", "Hello!", recovered.GetContent())
+	}
+}
+
+func TestEditor_CompactWithoutJournal(t *testing.T) {
+	editor := usecase.NewEditor(&MockLogger{})
+	if err := editor.Compact(); !errors.Is(err, usecase.ErrNoJournal) {
+		t.Errorf("expected ErrNoJournal, got %v", err)
+	}
+}
+
+func TestEditor_JournalFailureRollsBack(t *testing.T) {
+	journal := &MockJournal{}
+	editor := usecase.NewEditor(&MockLogger{}, usecase.WithJournal(journal, appendCodec{}))
+	editor.Execute(&AppendCommand{Text: "A"})
+
+	errDisk := errors.New("disk full")
+	journal.Err = errDisk
+
+	if err := editor.Execute(&AppendCommand{Text: "B"}); !errors.Is(err, errDisk) {
+		t.Errorf("expected journal error, got %v", err)
+	}
+	if err := editor.Undo(); !errors.Is(err, errDisk) {
+		t.Errorf("expected journal error, got %v", err)
+	}
+	if editor.GetContent() != "A" || !editor.CanUndo() || editor.CanRedo() {
+		t.Errorf("expected state to match the journal, got %q", editor.GetContent())
+	}
+}
+
+func TestEditor_RejectsUnserializableCommand(t *testing.T) {
+	journal := &MockJournal{}
+	editor := usecase.NewEditor(&MockLogger{}, usecase.WithJournal(journal, appendCodec{}))
+	cmd := &MockCommand{}
+
+	if err := editor.Execute(cmd); !errors.Is(err, domain.ErrNotSerializable) {
+		t.Errorf("expected ErrNotSerializable, got %v", err)
+	}
+	if cmd.Executed {
+		t.Error("command should be rejected before it runs")
+	}
+}
+
+func TestRecover_KeepsMergeDecisions(t *testing.T) {
+	journal := &MockJournal{}
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	editor := usecase.NewEditor(&MockLogger{},
+		usecase.WithJournal(journal, appendCodec{}),
+		usecase.WithMerging(time.Second),
+		usecase.WithClock(clock.Now),
+	)
+	typeText(editor, "ab")
+	clock.t = clock.t.Add(time.Minute)
+	typeText(editor, "cd")
+
+	// Replay runs instantly and without merging enabled, yet rebuilds the same steps
+	recovered, err := usecase.Recover(&MockLogger{}, journal, appendCodec{})
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	recovered.Undo()
+	if recovered.GetContent() != "ab" {
+		t.Errorf("expected %q, got %q", "ab", recovered.GetContent())
+	}
+	recovered.Undo()
+	if recovered.GetContent() != "" || recovered.CanUndo() {
+		t.Errorf("expected two undo steps, got %q", recovered.GetContent())
+	}
+}
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"sync"
+
+	"command-example/domain"
+)
+
+// SharedEditor lets several clients edit one buffer concurrently.
+// Every operation is serialized by a mutex, and each client has its own cursor
+// and its own undo/redo history.
+//
+// Undo only reverts the client's own commands. If another client has since
+// edited the same text, the command no longer matches the buffer and Undo
+// returns domain.ErrBufferMismatch instead of corrupting it.
+type SharedEditor struct {
+	mu      sync.Mutex
+	buffer  *domain.Buffer
+	clients map[string]*Client
+	logger  domain.Logger
+	opts    []EditorOption
+}
+
+// NewSharedEditor builds a shared editor with an empty buffer.
+// The options are applied to every client's editor; journaling is not supported.
+func NewSharedEditor(logger domain.Logger, opts ...EditorOption) *SharedEditor {
+	return &SharedEditor{
+		buffer:  domain.NewBuffer(),
+		clients: make(map[string]*Client),
+		logger:  logger,
+		opts:    opts,
+	}
+}
+
+// Client returns the session for id, creating it on first use.
+func (s *SharedEditor) Client(id string) *Client {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.clients[id]; ok {
+		return c
+	}
+
+	editor := NewEditor(s.logger, s.opts...)
+	editor.buffer = s.buffer
+	editor.journal = nil
+
+	c := &Client{
+		id:     id,
+		shared: s,
+		editor: editor,
+		caret:  &domain.Mark{},
+	}
+	s.buffer.AttachMark(c.caret)
+	s.clients[id] = c
+	return c
+}
+
+// GetContent returns the current buffer content.
+func (s *SharedEditor) GetContent() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buffer.Content
+}
+
+// run executes fn on behalf of c while holding the lock.
+// The buffer's own cursor is swapped with the client's caret, so commands see
+// the client's cursor, while the other clients' carets shift with the edit.
+func (s *SharedEditor) run(c *Client, fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buffer.DetachMark(c.caret)
+	s.buffer.Cursor, s.buffer.Anchor = c.caret.Cursor, c.caret.Anchor
+	defer func() {
+		c.caret.Cursor, c.caret.Anchor = s.buffer.Cursor, s.buffer.Anchor
+		s.buffer.AttachMark(c.caret)
+	}()
+
+	return fn()
+}
+
+// Client is one participant of a SharedEditor.
+// Its methods are safe for concurrent use.
+type Client struct {
+	id     string
+	shared *SharedEditor
+	editor *Editor
+	caret  *domain.Mark
+}
+
+// ID returns the client identifier.
+func (c *Client) ID() string {
+	return c.id
+}
+
+// Execute performs a command at this client's cursor and records it in this client's history.
+func (c *Client) Execute(cmd domain.Command) error {
+	return c.shared.run(c, func() error { return c.editor.Execute(cmd) })
+}
+
+// Undo reverts this client's latest command.
+func (c *Client) Undo() error {
+	return c.shared.run(c, c.editor.Undo)
+}
+
+// Redo re-applies this client's latest undone command.
+func (c *Client) Redo() error {
+	return c.shared.run(c, c.editor.Redo)
+}
+
+// BeginGroup starts a transaction in this client's history.
+func (c *Client) BeginGroup(name string) error {
+	return c.shared.run(c, func() error { return c.editor.BeginGroup(name) })
+}
+
+// EndGroup closes this client's transaction.
+func (c *Client) EndGroup() error {
+	return c.shared.run(c, c.editor.EndGroup)
+}
+
+// AbortGroup rolls back this client's transaction.
+func (c *Client) AbortGroup() error {
+	return c.shared.run(c, c.editor.AbortGroup)
+}
+
+// CanUndo reports whether this client has a command to undo.
+func (c *Client) CanUndo() bool {
+	c.shared.mu.Lock()
+	defer c.shared.mu.Unlock()
+	return c.editor.CanUndo()
+}
+
+// CanRedo reports whether this client has a command to redo.
+func (c *Client) CanRedo() bool {
+	c.shared.mu.Lock()
+	defer c.shared.mu.Unlock()
+	return c.editor.CanRedo()
+}
+
+// Cursor returns this client's cursor position in runes.
+func (c *Client) Cursor() int {
+	c.shared.mu.Lock()
+	defer c.shared.mu.Unlock()
+	return c.caret.Cursor
+}
@@ -0,0 +1,203 @@
+package usecase_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	"command-example/adapter"
+	"command-example/domain"
+	"command-example/usecase"
+)
+
+// TypeCommand inserts text at the cursor, like a keystroke.
+type TypeCommand struct {
+	Text string
+	at   int
+}
+
+func (c TypeCommand) Do(b *domain.Buffer) (domain.Command, error) {
+	c.at = b.InsertAt(b.Cursor, c.Text)
+	return c, nil
+}
+
+func (c TypeCommand) Undo(b *domain.Buffer) error {
+	end := c.at + utf8.RuneCountInString(c.Text)
+	if end > b.Len() || b.Text(c.at, end) != c.Text {
+		return domain.ErrBufferMismatch
+	}
+	b.DeleteRange(c.at, end)
+	return nil
+}
+
+// MoveCommand moves the cursor.
+type MoveCommand struct {
+	Pos  int
+	from int
+}
+
+func (c MoveCommand) Do(b *domain.Buffer) (domain.Command, error) {
+	c.from = b.Cursor
+	b.MoveCursor(c.Pos)
+	return c, nil
+}
+
+func (c MoveCommand) Undo(b *domain.Buffer) error {
+	b.MoveCursor(c.from)
+	return nil
+}
+
+func TestSharedEditor_IndependentCursors(t *testing.T) {
+	shared := usecase.NewSharedEditor(&MockLogger{})
+	alice := shared.Client("alice")
+	bob := shared.Client("bob")
+
+	alice.Execute(TypeCommand{Text: "world"})
+	bob.Execute(MoveCommand{Pos: 0})
+	bob.Execute(TypeCommand{Text: "hello "})
+
+	if got := shared.GetContent(); got != "hello world" {
+		t.Fatalf("unexpected content: %q", got)
+	}
+	// Bob typed before Alice's cursor, so Alice's cursor moved with the text
+	if alice.Cursor() != 11 || bob.Cursor() != 6 {
+		t.Errorf("unexpected cursors: alice=%d bob=%d", alice.Cursor(), bob.Cursor())
+	}
+
+	alice.Execute(TypeCommand{Text: "!"})
+	if got := shared.GetContent(); got != "hello world!" {
+		t.Errorf("unexpected content: %q", got)
+	}
+}
+
+func TestSharedEditor_PerClientUndo(t *testing.T) {
+	shared := usecase.NewSharedEditor(&MockLogger{})
+	alice := shared.Client("alice")
+	bob := shared.Client("bob")
+
+	alice.Execute(TypeCommand{Text: "A"})
+	bob.Execute(MoveCommand{Pos: 1})
+	bob.Execute(TypeCommand{Text: "B"})
+
+	if err := alice.Undo(); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if got := shared.GetContent(); got != "B" {
+		t.Errorf("expected only Alice's edit to be undone, got %q", got)
+	}
+	if !bob.CanUndo() || alice.CanUndo() || !alice.CanRedo() {
+		t.Error("histories should be independent")
+	}
+	if shared.Client("alice") != alice {
+		t.Error("Client should return the existing session")
+	}
+}
+
+func TestSharedEditor_UndoConflict(t *testing.T) {
+	shared := usecase.NewSharedEditor(&MockLogger{})
+	alice := shared.Client("alice")
+	bob := shared.Client("bob")
+
+	alice.Execute(TypeCommand{Text: "abc"})
+	bob.Execute(MoveCommand{Pos: 0})
+	bob.Execute(TypeCommand{Text: "x"})
+
+	// Alice's text moved; her recorded position no longer matches
+	if err := alice.Undo(); !errors.Is(err, domain.ErrBufferMismatch) {
+		t.Errorf("expected ErrBufferMismatch, got %v", err)
+	}
+	if got := shared.GetContent(); got != "xabc" {
+		t.Errorf("buffer should be untouched, got %q", got)
+	}
+}
+
+func TestSharedEditor_UndoDeleteConflict(t *testing.T) {
+	deletes := []struct {
+		name string
+		del  domain.Command
+	}{
+		{"Delete", adapter.NewDeleteCommand(1, &MockLogger{})},
+		{"DeleteRange", adapter.NewDeleteRangeCommand(4, 5, &MockLogger{})},
+	}
+	edits := []struct {
+		name  string
+		edits []domain.Command // Bob's edits to "hell"
+		want  string
+	}{
+		// Restoring "o" at the old end would land after Bob's text
+		{"Appended", []domain.Command{adapter.NewInsertCommand("X", &MockLogger{})}, "hellX"},
+		// Same length as before, but "o" would follow Bob's "X"
+		{"Replaced Same Length", []domain.Command{
+			adapter.NewDeleteRangeCommand(3, 4, &MockLogger{}),
+			adapter.NewInsertAtCommand(3, "X", &MockLogger{}),
+		}, "helX"},
+	}
+
+	for _, d := range deletes {
+		for _, e := range edits {
+			t.Run(d.name+" "+e.name, func(t *testing.T) {
+				shared := usecase.NewSharedEditor(&MockLogger{})
+				alice := shared.Client("alice")
+				bob := shared.Client("bob")
+
+				if err := alice.Execute(adapter.NewInsertCommand("hello", &MockLogger{})); err != nil {
+					t.Fatal(err)
+				}
+				if err := alice.Execute(d.del); err != nil {
+					t.Fatal(err)
+				}
+				for _, cmd := range e.edits {
+					if err := bob.Execute(cmd); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				if err := alice.Undo(); !errors.Is(err, domain.ErrBufferMismatch) {
+					t.Errorf("expected ErrBufferMismatch, got %v", err)
+				}
+				if got := shared.GetContent(); got != e.want {
+					t.Errorf("buffer should be untouched, got %q", got)
+				}
+			})
+		}
+	}
+}
+
+func TestSharedEditor_ConcurrentStress(t *testing.T) {
+	const (
+		clients    = 8
+		iterations = 200
+	)
+	shared := usecase.NewSharedEditor(&MockLogger{})
+
+	var wg sync.WaitGroup
+	net := make([]int, clients)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := shared.Client(fmt.Sprintf("client-%d", i))
+			for j := range iterations {
+				if err := c.Execute(TypeCommand{Text: "x"}); err == nil {
+					net[i]++
+				}
+				if j%3 == 0 && c.Undo() == nil {
+					net[i]--
+				}
+				_ = c.CanUndo()
+				_ = shared.GetContent()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	want := 0
+	for _, n := range net {
+		want += n
+	}
+	if got := utf8.RuneCountInString(shared.GetContent()); got != want {
+		t.Errorf("expected %d runes, got %d", want, got)
+	}
+}
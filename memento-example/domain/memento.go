@@ -1,5 +1,7 @@
 package domain
 
+import "errors"
+
 // Memento holds the saved state for the editor.
 type Memento struct {
 	state string
@@ -27,3 +29,10 @@ type Editor interface {
 type Logger interface {
 	Log(message string)
 }
+
+// Checkpoint errors
+var (
+	ErrEmptyTag           = errors.New("checkpoint tag is empty")
+	ErrTagExists          = errors.New("checkpoint tag already exists")
+	ErrCheckpointNotFound = errors.New("checkpoint not found")
+)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"memento-example/adapter"
 	"memento-example/usecase"
+	"time"
 )
 
 func main() {
@@ -33,4 +34,57 @@ func main() {
 	// Undo again
 	service.Undo()
 	fmt.Printf("Restored to State 1: %s\n", editor.GetContent())
+
+	// Redo goes forward again, up to the unsaved text
+	service.Redo()
+	service.Redo()
+	fmt.Printf("Redone: %s\n", editor.GetContent())
+
+	// 4. Named checkpoints and a retention policy
+	fmt.Println("\n--- Named checkpoints ---")
+	draft := &adapter.Editor{}
+	drafts := usecase.NewWriterService(draft, logger, usecase.WithRetention(usecase.RetentionPolicy{MaxCount: 3}))
+
+	drafts.Write("Dear team,")
+	if err := drafts.SaveAs("greeting"); err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
+	}
+	drafts.Write("the release is ready.")
+	drafts.Save()
+	drafts.Write("Thanks!")
+	if err := drafts.SaveAs("final"); err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
+	}
+
+	if err := drafts.RestoreTo("greeting"); err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
+	}
+	fmt.Printf("Restored to greeting: %s\n", draft.GetContent())
+
+	// One more save goes over the limit of 3, so the oldest checkpoint is dropped
+	drafts.Write("Hello everyone.")
+	drafts.Save()
+	printHistory(drafts)
+	if err := drafts.RestoreTo("greeting"); err != nil {
+		fmt.Printf("error: %v\n", err)
+	}
+}
+
+// printHistory lists the checkpoints, marking the one the content is based on.
+func printHistory(service *usecase.WriterService) {
+	current, _ := service.Current()
+	for i, c := range service.History() {
+		marker := " "
+		if i == current {
+			marker = "*"
+		}
+		tag := c.Tag
+		if tag == "" {
+			tag = "-"
+		}
+		fmt.Printf("%s %d %-8s %s %q\n", marker, i, tag, c.SavedAt.Format(time.TimeOnly), c.Memento.State())
+	}
 }
@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"time"
+
+	"memento-example/domain"
+)
+
+// Checkpoint is one saved editor state in the history.
+type Checkpoint struct {
+	Tag     string // Name given with SaveAs; empty for Save
+	SavedAt time.Time
+	Memento *domain.Memento
+}
+
+// RetentionPolicy limits how many checkpoints the history keeps.
+// A zero field means no limit. The latest checkpoint is always kept.
+type RetentionPolicy struct {
+	MaxCount int           // The oldest checkpoints are dropped beyond this count
+	MaxAge   time.Duration // Checkpoints saved longer ago than this are dropped
+}
+
+// expired returns how many of the oldest checkpoints the policy drops at now.
+func (p RetentionPolicy) expired(checkpoints []Checkpoint, now time.Time) int {
+	drop := 0
+	if p.MaxCount > 0 && len(checkpoints) > p.MaxCount {
+		drop = len(checkpoints) - p.MaxCount
+	}
+	if p.MaxAge > 0 {
+		cutoff := now.Add(-p.MaxAge)
+		for drop < len(checkpoints) && checkpoints[drop].SavedAt.Before(cutoff) {
+			drop++
+		}
+	}
+	return min(drop, max(len(checkpoints)-1, 0))
+}
+
+// position is where the editor content stands in the history, kept so Redo
+// can return to it.
+type position struct {
+	memento *domain.Memento
+	base    int
+	dirty   bool
+}
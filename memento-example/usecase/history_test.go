@@ -0,0 +1,272 @@
+package usecase_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"memento-example/domain"
+	"memento-example/usecase"
+)
+
+// FakeClock is a clock the test moves forward by hand
+type FakeClock struct {
+	Now time.Time
+}
+
+func (c *FakeClock) Advance(d time.Duration) {
+	c.Now = c.Now.Add(d)
+}
+
+func newClock() *FakeClock {
+	return &FakeClock{Now: time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)}
+}
+
+func tags(history []usecase.Checkpoint) []string {
+	var out []string
+	for _, c := range history {
+		out = append(out, c.Tag)
+	}
+	return out
+}
+
+func TestWriterService_UndoRedo(t *testing.T) {
+	editor := &MockEditor{}
+	service := usecase.NewWriterService(editor, &MockLogger{})
+
+	service.Write("A")
+	service.Save()
+	service.Write("B")
+	service.Save()
+	service.Write("C")
+
+	steps := []struct {
+		action string
+		want   string
+	}{
+		{"undo", "AB"}, // Unsaved changes go first
+		{"undo", "A"},
+		{"undo", "A"}, // Nothing older
+		{"redo", "AB"},
+		{"redo", "ABC"},
+		{"redo", "ABC"}, // Nothing newer
+		{"undo", "AB"},
+	}
+	for i, step := range steps {
+		if step.action == "undo" {
+			service.Undo()
+		} else {
+			service.Redo()
+		}
+		if editor.Content != step.want {
+			t.Fatalf("step %d (%s): content = %q, want %q", i, step.action, editor.Content, step.want)
+		}
+	}
+
+	// Typing after an undo discards the redo branch
+	service.Write("D")
+	if service.CanRedo() {
+		t.Error("expected the redo branch to be discarded")
+	}
+	service.Redo()
+	if editor.Content != "ABD" {
+		t.Errorf("content = %q, want %q", editor.Content, "ABD")
+	}
+}
+
+func TestWriterService_RestoreTo(t *testing.T) {
+	editor := &MockEditor{}
+	service := usecase.NewWriterService(editor, &MockLogger{})
+
+	service.Write("draft")
+	if err := service.SaveAs("v1"); err != nil {
+		t.Fatal(err)
+	}
+	service.Write(" edited")
+	service.Save()
+	service.Write(" more")
+	if err := service.SaveAs("v2"); err != nil {
+		t.Fatal(err)
+	}
+	service.Write(" unsaved")
+
+	if err := service.RestoreTo("v1"); err != nil {
+		t.Fatalf("RestoreTo() error = %v", err)
+	}
+	if editor.Content != "draft" {
+		t.Errorf("content = %q, want %q", editor.Content, "draft")
+	}
+	if index, modified := service.Current(); index != 0 || modified {
+		t.Errorf("Current() = %d, %t, want 0, false", index, modified)
+	}
+
+	// Checkpoints stay in the history, so a tag can be restored again
+	if err := service.RestoreTo("v2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.RestoreTo("v1"); err != nil {
+		t.Fatal(err)
+	}
+	// Each restore can be redone, back to the unsaved text
+	for range 3 {
+		service.Redo()
+	}
+	if editor.Content != "draft edited more unsaved" {
+		t.Errorf("after redo content = %q, want the unsaved text back", editor.Content)
+	}
+
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{"Unknown Tag", service.RestoreTo("v3"), domain.ErrCheckpointNotFound},
+		{"Duplicate Tag", service.SaveAs("v1"), domain.ErrTagExists},
+		{"Empty Tag", service.SaveAs(""), domain.ErrEmptyTag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", tt.err, tt.wantErr)
+			}
+		})
+	}
+	if len(service.History()) != 3 {
+		t.Errorf("failed saves must not add checkpoints, got %d", len(service.History()))
+	}
+}
+
+func TestWriterService_History(t *testing.T) {
+	clock := newClock()
+	editor := &MockEditor{}
+	service := usecase.NewWriterService(editor, &MockLogger{}, usecase.WithClock(func() time.Time { return clock.Now }))
+
+	service.Write("A")
+	service.Save()
+	clock.Advance(time.Minute)
+	service.Write("B")
+	if err := service.SaveAs("final"); err != nil {
+		t.Fatal(err)
+	}
+
+	history := service.History()
+	if len(history) != 2 {
+		t.Fatalf("history has %d checkpoints, want 2", len(history))
+	}
+	want := []struct {
+		tag     string
+		savedAt time.Time
+		state   string
+	}{
+		{"", clock.Now.Add(-time.Minute), "A"},
+		{"final", clock.Now, "AB"},
+	}
+	for i, w := range want {
+		c := history[i]
+		if c.Tag != w.tag || !c.SavedAt.Equal(w.savedAt) || c.Memento.State() != w.state {
+			t.Errorf("checkpoint %d = {%q %v %q}, want {%q %v %q}", i, c.Tag, c.SavedAt, c.Memento.State(), w.tag, w.savedAt, w.state)
+		}
+	}
+}
+
+func TestWriterService_Retention(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   usecase.RetentionPolicy
+		wantTags []string
+	}{
+		{"Unlimited", usecase.RetentionPolicy{}, []string{"v1", "v2", "v3", "v4"}},
+		{"Max Count", usecase.RetentionPolicy{MaxCount: 2}, []string{"v3", "v4"}},
+		// Saved one minute apart: v1 is 3 minutes old at the last save
+		{"Max Age", usecase.RetentionPolicy{MaxAge: 150 * time.Second}, []string{"v2", "v3", "v4"}},
+		{"Both", usecase.RetentionPolicy{MaxCount: 3, MaxAge: 90 * time.Second}, []string{"v3", "v4"}},
+		{"Latest Is Always Kept", usecase.RetentionPolicy{MaxAge: time.Nanosecond}, []string{"v4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newClock()
+			editor := &MockEditor{}
+			service := usecase.NewWriterService(editor, &MockLogger{},
+				usecase.WithRetention(tt.policy),
+				usecase.WithClock(func() time.Time { return clock.Now }))
+
+			for i, tag := range []string{"v1", "v2", "v3", "v4"} {
+				if i > 0 {
+					clock.Advance(time.Minute)
+				}
+				service.Write(tag)
+				if err := service.SaveAs(tag); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := tags(service.History()); !slices.Equal(got, tt.wantTags) {
+				t.Fatalf("kept %v, want %v", got, tt.wantTags)
+			}
+			if index, _ := service.Current(); index != len(tt.wantTags)-1 {
+				t.Errorf("Current() index = %d, want the latest checkpoint", index)
+			}
+			// Undo stops at the oldest kept checkpoint
+			for range 4 {
+				service.Undo()
+			}
+			if want := "v1v2v3v4"[:2*(5-len(tt.wantTags))]; editor.Content != want {
+				t.Errorf("content = %q, want %q", editor.Content, want)
+			}
+			if err := service.RestoreTo("v1"); len(tt.wantTags) < 4 && !errors.Is(err, domain.ErrCheckpointNotFound) {
+				t.Errorf("RestoreTo(dropped) error = %v, want ErrCheckpointNotFound", err)
+			}
+		})
+	}
+}
+
+func TestWriterService_RetentionWithoutSaving(t *testing.T) {
+	clock := newClock()
+	editor := &MockEditor{}
+	service := usecase.NewWriterService(editor, &MockLogger{},
+		usecase.WithRetention(usecase.RetentionPolicy{MaxAge: 3 * time.Minute}),
+		usecase.WithClock(func() time.Time { return clock.Now }))
+
+	for i, tag := range []string{"v1", "v2", "v3"} {
+		if i > 0 {
+			clock.Advance(time.Minute)
+		}
+		service.Write(tag)
+		if err := service.SaveAs(tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := service.RestoreTo("v1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// v1 expires while nothing is saved
+	clock.Advance(2 * time.Minute)
+	if got, want := tags(service.History()), []string{"v2", "v3"}; !slices.Equal(got, want) {
+		t.Fatalf("History() = %v, want %v", got, want)
+	}
+	if err := service.RestoreTo("v1"); !errors.Is(err, domain.ErrCheckpointNotFound) {
+		t.Errorf("RestoreTo(expired) error = %v, want ErrCheckpointNotFound", err)
+	}
+
+	// Redo still returns to the latest checkpoint after the history shrank
+	service.Redo()
+	if index, modified := service.Current(); editor.Content != "v1v2v3" || index != 1 || modified {
+		t.Fatalf("after Redo: content = %q, Current() = %d, %v", editor.Content, index, modified)
+	}
+	if err := service.RestoreTo("v2"); err != nil || editor.Content != "v1v2" {
+		t.Fatalf("RestoreTo(v2) = %v, content %q", err, editor.Content)
+	}
+
+	// Undo does not step back to a checkpoint that expired meanwhile
+	clock.Advance(10 * time.Minute)
+	service.Undo()
+	if editor.Content != "v1v2" {
+		t.Errorf("content = %q, want it unchanged", editor.Content)
+	}
+	if got, want := tags(service.History()), []string{"v3"}; !slices.Equal(got, want) {
+		t.Errorf("History() = %v, want %v", got, want)
+	}
+}
@@ -1,49 +1,191 @@
 package usecase
 
-import "memento-example/domain"
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"memento-example/domain"
+)
 
 // WriterService is the caretaker managing mementos.
+// Every Save adds a checkpoint to the history; SaveAs also names it so it can
+// be restored later with RestoreTo. Undo steps back through the checkpoints
+// and Redo returns to where the editor was before.
 type WriterService struct {
-	editor  domain.Editor
-	history []*domain.Memento
-	logger  domain.Logger
+	editor      domain.Editor
+	checkpoints []Checkpoint // Oldest first
+	base        int          // Checkpoint the content was saved as or restored from; -1 if none
+	dirty       bool         // The content changed since base
+	redo        []position
+	retention   RetentionPolicy
+	now         func() time.Time
+	logger      domain.Logger
+}
+
+// WriterOption configures a WriterService.
+type WriterOption func(*WriterService)
+
+// WithRetention limits the checkpoints kept in the history.
+// The policy is applied on every save, and again before the history is
+// listed or restored from so a checkpoint past MaxAge is never returned.
+func WithRetention(policy RetentionPolicy) WriterOption {
+	return func(s *WriterService) {
+		s.retention = policy
+	}
 }
 
-// NewWriterService builds a WriterService.
-func NewWriterService(editor domain.Editor, logger domain.Logger) *WriterService {
-	return &WriterService{
-		editor:  editor,
-		history: make([]*domain.Memento, 0),
-		logger:  logger,
+// WithClock replaces the clock used for checkpoint timestamps.
+func WithClock(now func() time.Time) WriterOption {
+	return func(s *WriterService) {
+		s.now = now
 	}
 }
 
+// NewWriterService builds a WriterService with an unlimited history.
+func NewWriterService(editor domain.Editor, logger domain.Logger, opts ...WriterOption) *WriterService {
+	s := &WriterService{
+		editor: editor,
+		base:   -1,
+		now:    time.Now,
+		logger: logger,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
 // Write appends text to the editor.
+// New text discards anything that could have been redone.
 func (s *WriterService) Write(text string) {
 	s.editor.Type(text)
+	s.dirty = true
+	s.redo = nil
 	s.logger.Log("Typed: " + text)
 }
 
-// Save stores the current editor state.
+// Save stores the current editor state as an untagged checkpoint.
 func (s *WriterService) Save() {
-	m := s.editor.CreateMemento()
-	s.history = append(s.history, m)
-	s.logger.Log("State Saved")
+	s.save("")
+}
+
+// SaveAs stores the current editor state as a checkpoint named tag.
+// Tags are unique among the checkpoints in the history.
+func (s *WriterService) SaveAs(tag string) error {
+	if tag == "" {
+		return domain.ErrEmptyTag
+	}
+	if s.find(tag) >= 0 {
+		return fmt.Errorf("%w: %q", domain.ErrTagExists, tag)
+	}
+	s.save(tag)
+	return nil
+}
+
+func (s *WriterService) save(tag string) {
+	s.checkpoints = append(s.checkpoints, Checkpoint{Tag: tag, SavedAt: s.now(), Memento: s.editor.CreateMemento()})
+	s.base = len(s.checkpoints) - 1
+	s.dirty = false
+	s.redo = nil
+	if tag == "" {
+		s.logger.Log("State Saved")
+	} else {
+		s.logger.Log("State Saved as " + tag)
+	}
+	s.prune()
+}
+
+// prune drops the checkpoints the retention policy no longer keeps.
+// Positions pointing at a dropped checkpoint no longer have one to go back to.
+func (s *WriterService) prune() {
+	drop := s.retention.expired(s.checkpoints, s.now())
+	if drop == 0 {
+		return
+	}
+	s.checkpoints = slices.Clone(s.checkpoints[drop:])
+	s.base = max(s.base-drop, -1)
+	for i := range s.redo {
+		s.redo[i].base = max(s.redo[i].base-drop, -1)
+	}
+	s.logger.Log(fmt.Sprintf("Dropped %d old checkpoint(s)", drop))
 }
 
-// Undo restores the editor to the previous saved state.
+// Undo restores the editor to the previous saved state: the checkpoint the
+// content is based on if it has unsaved changes, otherwise the one before it.
 func (s *WriterService) Undo() {
-	if len(s.history) == 0 {
+	s.prune()
+	target := s.base
+	if !s.dirty {
+		target--
+	}
+	if target < 0 {
 		s.logger.Log("No history to undo")
 		return
 	}
-	// Get last saved state
-	lastIndex := len(s.history) - 1
-	m := s.history[lastIndex]
+	s.restore(target)
+	s.logger.Log("Restored to previous state")
+}
 
-	// Remove from history (pop)
-	s.history = s.history[:lastIndex]
+// RestoreTo restores the editor to the checkpoint named tag.
+// Like Undo, it can be reverted with Redo.
+func (s *WriterService) RestoreTo(tag string) error {
+	s.prune()
+	i := s.find(tag)
+	if i < 0 {
+		return fmt.Errorf("%w: %q", domain.ErrCheckpointNotFound, tag)
+	}
+	s.restore(i)
+	s.logger.Log("Restored to " + tag)
+	return nil
+}
 
-	s.editor.Restore(m)
-	s.logger.Log("Restored to previous state")
+func (s *WriterService) restore(i int) {
+	s.redo = append(s.redo, position{memento: s.editor.CreateMemento(), base: s.base, dirty: s.dirty})
+	s.editor.Restore(s.checkpoints[i].Memento)
+	s.base = i
+	s.dirty = false
+}
+
+// Redo reverts the latest Undo or RestoreTo.
+func (s *WriterService) Redo() {
+	if len(s.redo) == 0 {
+		s.logger.Log("No history to redo")
+		return
+	}
+	last := len(s.redo) - 1
+	pos := s.redo[last]
+	s.redo = s.redo[:last]
+
+	s.editor.Restore(pos.memento)
+	s.base = pos.base
+	s.dirty = pos.dirty
+	s.logger.Log("Redone")
+}
+
+// CanUndo reports whether Undo would have an effect.
+func (s *WriterService) CanUndo() bool {
+	return s.base > 0 || (s.base == 0 && s.dirty)
+}
+
+// CanRedo reports whether Redo would have an effect.
+func (s *WriterService) CanRedo() bool {
+	return len(s.redo) > 0
+}
+
+// History returns the checkpoints the retention policy still keeps, oldest first.
+func (s *WriterService) History() []Checkpoint {
+	s.prune()
+	return slices.Clone(s.checkpoints)
+}
+
+// Current returns the index in History of the checkpoint the editor content
+// was saved as or restored from, and whether it has changed since.
+// The index is -1 before the first save.
+func (s *WriterService) Current() (index int, modified bool) {
+	return s.base, s.dirty
+}
+
+func (s *WriterService) find(tag string) int {
+	return slices.IndexFunc(s.checkpoints, func(c Checkpoint) bool { return c.Tag == tag })
 }
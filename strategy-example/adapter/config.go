@@ -0,0 +1,27 @@
+package adapter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"strategy-example/domain"
+)
+
+// LoadCheckoutConfig reads the default payment and shipping methods from a JSON file:
+//
+//	{
+//	  "payment":  {"name": "paypal", "config": {"email": "user@example.com"}},
+//	  "shipping": {"name": "express", "config": {"carrier": "DHL Express"}}
+//	}
+func LoadCheckoutConfig(path string) (domain.CheckoutConfig, error) {
+	var cfg domain.CheckoutConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("checkout config: %w", err)
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("checkout config: %w", err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,51 @@
+package adapter
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"strategy-example/domain"
+)
+
+// Ensure implementation
+var _ domain.EventSink = (*LogEventSink)(nil)
+
+// LogEventSink writes each checkout event to a Logger as one line of
+// key=value pairs, e.g.
+//
+//	time=2025-01-02T15:04:05Z event=payment_succeeded order=order-1 amount="64.00 USD" transaction=cc-1a2b
+type LogEventSink struct {
+	logger domain.Logger
+}
+
+// NewLogEventSink creates a LogEventSink.
+func NewLogEventSink(logger domain.Logger) *LogEventSink {
+	return &LogEventSink{logger: logger}
+}
+
+func (s *LogEventSink) Publish(event domain.CheckoutEvent) {
+	fields := []string{
+		"time=" + event.Time.Format(time.RFC3339),
+		"event=" + string(event.Type),
+	}
+	add := func(key, value string) {
+		if value == "" {
+			return
+		}
+		// Quote values that would otherwise be hard to split
+		if strings.ContainsAny(value, " \"=") {
+			value = strconv.Quote(value)
+		}
+		fields = append(fields, key+"="+value)
+	}
+	add("order", event.OrderID)
+	add("amount", event.Amount.String())
+	add("destination", event.Destination)
+	add("transaction", event.TransactionID)
+	add("method", event.Method)
+	if event.Err != nil {
+		add("error", event.Err.Error())
+	}
+	s.logger.Log(strings.Join(fields, " "))
+}
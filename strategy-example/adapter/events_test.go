@@ -0,0 +1,51 @@
+package adapter_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"strategy-example/adapter"
+	"strategy-example/domain"
+)
+
+func TestLogEventSink_Publish(t *testing.T) {
+	at := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name  string
+		event domain.CheckoutEvent
+		want  string
+	}{
+		{
+			name: "Started",
+			event: domain.CheckoutEvent{
+				Type:        domain.EventPaymentStarted,
+				Amount:      domain.MustParseMoney("64.00", domain.USD),
+				Destination: "Tokyo",
+				Time:        at,
+			},
+			want: `time=2025-01-02T15:04:05Z event=payment_started amount="64.00 USD" destination=Tokyo`,
+		},
+		{
+			name: "Failed",
+			event: domain.CheckoutEvent{
+				Type:    domain.EventPaymentFailed,
+				OrderID: "order-1",
+				Amount:  domain.NewMoney(500, domain.JPY),
+				Err:     errors.New("card declined"),
+				Time:    at,
+			},
+			want: `time=2025-01-02T15:04:05Z event=payment_failed order=order-1 amount="500 JPY" error="card declined"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &MockLogger{}
+			adapter.NewLogEventSink(logger).Publish(tt.event)
+			if len(logger.Messages) != 1 || logger.Messages[0] != tt.want {
+				t.Errorf("logged %q, want %q", logger.Messages, tt.want)
+			}
+		})
+	}
+}
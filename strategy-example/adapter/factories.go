@@ -0,0 +1,93 @@
+package adapter
+
+import (
+	"fmt"
+	"strconv"
+
+	"strategy-example/domain"
+	"strategy-example/usecase"
+)
+
+// Names under which the built-in strategies are registered.
+const (
+	PaymentCreditCard = "credit_card"
+	PaymentPayPal     = "paypal"
+	PaymentBitcoin    = "bitcoin"
+	ShippingStandard  = "standard"
+	ShippingExpress   = "express"
+)
+
+// RegisterStrategies registers every built-in strategy in r.
+// The strategies it builds report to logger.
+//
+// Config keys:
+//   - credit_card: card_number, exp_month, exp_year, cvv
+//   - paypal:      email
+//   - bitcoin:     wallet
+//   - standard:    carrier, transit_days, and optionally a tariff
+//   - express:     carrier, and optionally a tariff
+//
+// A tariff is given as currency, base, per_kg and max_weight_g
+// (e.g. "JPY", "800", "200", "25000"); without it the default tariff applies.
+func RegisterStrategies(r *usecase.StrategyRegistry, logger domain.Logger) {
+	r.RegisterPayment(PaymentCreditCard, func(cfg domain.MethodConfig) (domain.PaymentMethod, error) {
+		month, err := strconv.Atoi(cfg["exp_month"])
+		if err != nil {
+			return nil, fmt.Errorf("exp_month: %w", err)
+		}
+		year, err := strconv.Atoi(cfg["exp_year"])
+		if err != nil {
+			return nil, fmt.Errorf("exp_year: %w", err)
+		}
+		// Return nil explicitly on error: a nil *CreditCardStrategy is not a nil interface
+		card, err := NewCreditCardStrategy(CardDetails{
+			Number:   cfg["card_number"],
+			ExpMonth: month,
+			ExpYear:  year,
+			CVV:      cfg["cvv"],
+		}, logger)
+		if err != nil {
+			return nil, err
+		}
+		return card, nil
+	})
+	r.RegisterPayment(PaymentPayPal, func(cfg domain.MethodConfig) (domain.PaymentMethod, error) {
+		paypal, err := NewPayPalStrategy(cfg["email"], logger)
+		if err != nil {
+			return nil, err
+		}
+		return paypal, nil
+	})
+	r.RegisterPayment(PaymentBitcoin, func(cfg domain.MethodConfig) (domain.PaymentMethod, error) {
+		bitcoin, err := NewBitcoinStrategy(cfg["wallet"], logger)
+		if err != nil {
+			return nil, err
+		}
+		return bitcoin, nil
+	})
+	r.RegisterShipping(ShippingStandard, func(cfg domain.MethodConfig) (domain.ShippingMethod, error) {
+		days, err := strconv.Atoi(cfg["transit_days"])
+		if err != nil {
+			return nil, fmt.Errorf("transit_days: %w", err)
+		}
+		tariff, err := tariffFromConfig(cfg, DefaultStandardTariff)
+		if err != nil {
+			return nil, err
+		}
+		return NewStandardShippingStrategy(cfg["carrier"], days, tariff, logger), nil
+	})
+	r.RegisterShipping(ShippingExpress, func(cfg domain.MethodConfig) (domain.ShippingMethod, error) {
+		tariff, err := tariffFromConfig(cfg, DefaultExpressTariff)
+		if err != nil {
+			return nil, err
+		}
+		return NewExpressShippingStrategy(cfg["carrier"], tariff, logger), nil
+	})
+}
+
+// NewDefaultRegistry returns a registry holding the built-in strategies.
+func NewDefaultRegistry(logger domain.Logger) *usecase.StrategyRegistry {
+	r := usecase.NewStrategyRegistry()
+	RegisterStrategies(r, logger)
+	return r
+}
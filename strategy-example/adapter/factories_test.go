@@ -0,0 +1,89 @@
+package adapter_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"strategy-example/adapter"
+	"strategy-example/domain"
+)
+
+func TestDefaultRegistry(t *testing.T) {
+	r := adapter.NewDefaultRegistry(&MockLogger{})
+
+	tests := []struct {
+		name    string
+		build   func() error
+		wantErr bool
+	}{
+		{"Credit Card", func() error {
+			_, err := r.Payment(domain.MethodSelection{Name: adapter.PaymentCreditCard, Config: domain.MethodConfig{"card_number": "4111111111111111", "exp_month": "12", "exp_year": "2030", "cvv": "123"}})
+			return err
+		}, false},
+		{"Credit Card Bad Expiry", func() error {
+			_, err := r.Payment(domain.MethodSelection{Name: adapter.PaymentCreditCard, Config: domain.MethodConfig{"card_number": "4111111111111111", "exp_month": "13", "exp_year": "2030", "cvv": "123"}})
+			return err
+		}, true},
+		{"PayPal", func() error {
+			_, err := r.Payment(domain.MethodSelection{Name: adapter.PaymentPayPal, Config: domain.MethodConfig{"email": "user@example.com"}})
+			return err
+		}, false},
+		{"Bitcoin", func() error {
+			_, err := r.Payment(domain.MethodSelection{Name: adapter.PaymentBitcoin, Config: domain.MethodConfig{"wallet": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"}})
+			return err
+		}, false},
+		{"Bitcoin Bad Wallet", func() error {
+			_, err := r.Payment(domain.MethodSelection{Name: adapter.PaymentBitcoin, Config: domain.MethodConfig{"wallet": "1A1z"}})
+			return err
+		}, true},
+		{"Standard", func() error {
+			_, err := r.Shipping(domain.MethodSelection{Name: adapter.ShippingStandard, Config: domain.MethodConfig{"carrier": "Japan Post", "transit_days": "5"}})
+			return err
+		}, false},
+		{"Standard Bad Transit Days", func() error {
+			_, err := r.Shipping(domain.MethodSelection{Name: adapter.ShippingStandard, Config: domain.MethodConfig{"carrier": "Japan Post", "transit_days": "soon"}})
+			return err
+		}, true},
+		{"Express", func() error {
+			_, err := r.Shipping(domain.MethodSelection{Name: adapter.ShippingExpress, Config: domain.MethodConfig{"carrier": "DHL"}})
+			return err
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.build(); (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadCheckoutConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "checkout.json")
+	data := `{
+  "payment":  {"name": "paypal", "config": {"email": "user@example.com"}},
+  "shipping": {"name": "express", "config": {"carrier": "DHL Express"}}
+}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := adapter.LoadCheckoutConfig(path)
+	if err != nil {
+		t.Fatalf("LoadCheckoutConfig() error = %v", err)
+	}
+	if cfg.Payment.Name != "paypal" || cfg.Payment.Config["email"] != "user@example.com" {
+		t.Errorf("unexpected payment selection: %+v", cfg.Payment)
+	}
+	if cfg.Shipping.Name != "express" || cfg.Shipping.Config["carrier"] != "DHL Express" {
+		t.Errorf("unexpected shipping selection: %+v", cfg.Shipping)
+	}
+
+	if _, err := adapter.LoadCheckoutConfig(filepath.Join(dir, "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected ErrNotExist, got %v", err)
+	}
+}
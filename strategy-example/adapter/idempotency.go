@@ -0,0 +1,79 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"strategy-example/domain"
+)
+
+// Ensure implementation
+var _ domain.IdempotencyStore = (*MemoryIdempotencyStore)(nil)
+
+// MemoryIdempotencyStore keeps idempotency records in memory.
+// It is safe for concurrent use; records live as long as the store.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]domain.IdempotencyRecord
+}
+
+// NewMemoryIdempotencyStore creates an empty store.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{records: make(map[string]domain.IdempotencyRecord)}
+}
+
+// Reserve claims key unless it is already known.
+func (s *MemoryIdempotencyStore) Reserve(ctx context.Context, key, fingerprint string) (domain.IdempotencyRecord, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.IdempotencyRecord{}, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.records[key]; ok {
+		return rec, false, nil
+	}
+	rec := domain.IdempotencyRecord{Key: key, Fingerprint: fingerprint}
+	s.records[key] = rec
+	return rec, true, nil
+}
+
+// Complete stores the receipt for a reserved key.
+func (s *MemoryIdempotencyStore) Complete(ctx context.Context, key string, receipt domain.Receipt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[key]
+	if !ok {
+		return fmt.Errorf("idempotency: key %q was not reserved", key)
+	}
+	rec.Done = true
+	rec.Receipt = receipt
+	s.records[key] = rec
+	return nil
+}
+
+// Fail marks a reserved key as failed with receipt still charged.
+func (s *MemoryIdempotencyStore) Fail(ctx context.Context, key string, receipt domain.Receipt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[key]
+	if !ok {
+		return fmt.Errorf("idempotency: key %q was not reserved", key)
+	}
+	rec.Done = true
+	rec.Failed = true
+	rec.Receipt = receipt
+	s.records[key] = rec
+	return nil
+}
+
+// Release forgets a key that has not been completed.
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.records[key]; ok && !rec.Done {
+		delete(s.records, key)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package adapter_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"strategy-example/adapter"
+	"strategy-example/domain"
+)
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	ctx := context.Background()
+	store := adapter.NewMemoryIdempotencyStore()
+
+	if _, reserved, err := store.Reserve(ctx, "k1", "fp"); err != nil || !reserved {
+		t.Fatalf("first Reserve() = %v, %v; want reserved", reserved, err)
+	}
+	rec, reserved, _ := store.Reserve(ctx, "k1", "fp")
+	if reserved || rec.Done {
+		t.Fatalf("second Reserve() = %+v, %v; want an in-progress record", rec, reserved)
+	}
+
+	receipt := domain.Receipt{TransactionID: "tx-1"}
+	if err := store.Complete(ctx, "k1", receipt); err != nil {
+		t.Fatal(err)
+	}
+	// Completed records survive Release
+	if err := store.Release(ctx, "k1"); err != nil {
+		t.Fatal(err)
+	}
+	rec, reserved, _ = store.Reserve(ctx, "k1", "fp")
+	if reserved || !rec.Done || rec.Receipt != receipt {
+		t.Errorf("Reserve() after Complete = %+v, %v", rec, reserved)
+	}
+
+	// Released in-progress records can be reserved again
+	store.Reserve(ctx, "k2", "fp")
+	store.Release(ctx, "k2")
+	if _, reserved, _ := store.Reserve(ctx, "k2", "fp"); !reserved {
+		t.Error("expected k2 to be reservable after Release")
+	}
+
+	// Failed records keep their charge and survive Release
+	store.Reserve(ctx, "k3", "fp")
+	if err := store.Fail(ctx, "k3", receipt); err != nil {
+		t.Fatal(err)
+	}
+	store.Release(ctx, "k3")
+	rec, reserved, _ = store.Reserve(ctx, "k3", "fp")
+	if reserved || !rec.Failed || rec.Receipt != receipt {
+		t.Errorf("Reserve() after Fail = %+v, %v", rec, reserved)
+	}
+
+	if err := store.Complete(ctx, "unknown", receipt); err == nil {
+		t.Error("expected error completing an unreserved key")
+	}
+	if err := store.Fail(ctx, "unknown", receipt); err == nil {
+		t.Error("expected error failing an unreserved key")
+	}
+}
+
+func TestMemoryIdempotencyStore_ConcurrentReserve(t *testing.T) {
+	store := adapter.NewMemoryIdempotencyStore()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	winners := 0
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, reserved, _ := store.Reserve(context.Background(), "order", "fp"); reserved {
+				mu.Lock()
+				winners++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if winners != 1 {
+		t.Errorf("winners = %d, want exactly 1", winners)
+	}
+}
@@ -0,0 +1,62 @@
+package adapter
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"strategy-example/domain"
+)
+
+// ledger issues receipts for one payment strategy and remembers them,
+// so that a transaction can be refunded exactly once.
+type ledger struct {
+	mu      sync.Mutex
+	prefix  string
+	settled map[string]bool // transaction ID -> refunded
+}
+
+func newLedger(prefix string) *ledger {
+	return &ledger{
+		prefix:  prefix,
+		settled: make(map[string]bool),
+	}
+}
+
+// issue records a new transaction and returns its receipt.
+func (l *ledger) issue(amount domain.Money) domain.Receipt {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r := domain.Receipt{
+		TransactionID: l.prefix + "-" + randomID(),
+		Amount:        amount,
+		Timestamp:     time.Now(),
+	}
+	l.settled[r.TransactionID] = false
+	return r
+}
+
+// refund marks the transaction as refunded.
+func (l *ledger) refund(r domain.Receipt) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	refunded, ok := l.settled[r.TransactionID]
+	if !ok {
+		return fmt.Errorf("%w: %q", domain.ErrUnknownTransaction, r.TransactionID)
+	}
+	if refunded {
+		return fmt.Errorf("%w: %q", domain.ErrAlreadyRefunded, r.TransactionID)
+	}
+	l.settled[r.TransactionID] = true
+	return nil
+}
+
+func randomID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b) // crypto/rand.Read never returns an error
+	return hex.EncodeToString(b)
+}
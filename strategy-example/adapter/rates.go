@@ -0,0 +1,90 @@
+package adapter
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+
+	"strategy-example/domain"
+)
+
+// Ensure implementation
+var _ domain.ExchangeRateProvider = (*StaticRateTable)(nil)
+
+// StaticRateTable is an in-memory ExchangeRateProvider with fixed rates.
+// A rate registered for USD→JPY is also used, inverted, for JPY→USD, and
+// pairs without a direct rate are crossed through a shared currency
+// (JPY→BTC via JPY→USD→BTC).
+type StaticRateTable struct {
+	mu    sync.RWMutex
+	rates map[[2]domain.Currency]*big.Rat
+}
+
+// NewStaticRateTable creates an empty rate table.
+func NewStaticRateTable() *StaticRateTable {
+	return &StaticRateTable{rates: make(map[[2]domain.Currency]*big.Rat)}
+}
+
+// Set registers how many units of `to` one unit of `from` buys.
+// The rate is a decimal string such as "150.25" so it is stored exactly.
+func (t *StaticRateTable) Set(from, to domain.Currency, rate string) error {
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return fmt.Errorf("exchange rate %s→%s: invalid rate %q", from, to, rate)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rates[[2]domain.Currency{from, to}] = r
+	return nil
+}
+
+// Rate returns the rate from one currency to another.
+func (t *StaticRateTable) Rate(from, to domain.Currency) (*big.Rat, error) {
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if r, ok := t.direct(from, to); ok {
+		return r, nil
+	}
+	for _, via := range t.currencies() {
+		first, ok1 := t.direct(from, via)
+		second, ok2 := t.direct(via, to)
+		if ok1 && ok2 {
+			return first.Mul(first, second), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no exchange rate %s→%s", domain.ErrUnsupportedCurrency, from, to)
+}
+
+// direct looks up a registered rate or the inverse of one. Callers hold t.mu.
+func (t *StaticRateTable) direct(from, to domain.Currency) (*big.Rat, bool) {
+	if r, ok := t.rates[[2]domain.Currency{from, to}]; ok {
+		return new(big.Rat).Set(r), true
+	}
+	if r, ok := t.rates[[2]domain.Currency{to, from}]; ok {
+		return new(big.Rat).Inv(r), true
+	}
+	return nil, false
+}
+
+// currencies lists every currency in the table in sorted order, so that
+// crossing always picks the same intermediate currency. Callers hold t.mu.
+func (t *StaticRateTable) currencies() []domain.Currency {
+	seen := make(map[domain.Currency]bool)
+	var out []domain.Currency
+	for pair := range t.rates {
+		for _, c := range pair {
+			if !seen[c] {
+				seen[c] = true
+				out = append(out, c)
+			}
+		}
+	}
+	slices.Sort(out)
+	return out
+}
@@ -0,0 +1,58 @@
+package adapter_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"strategy-example/adapter"
+	"strategy-example/domain"
+)
+
+func TestStaticRateTable_Rate(t *testing.T) {
+	table := adapter.NewStaticRateTable()
+	if err := table.Set(domain.USD, domain.JPY, "150"); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Set(domain.BTC, domain.USD, "62500"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		from    domain.Currency
+		to      domain.Currency
+		want    *big.Rat
+		wantErr error
+	}{
+		{"Identity", domain.EUR, domain.EUR, big.NewRat(1, 1), nil},
+		{"Direct", domain.USD, domain.JPY, big.NewRat(150, 1), nil},
+		{"Inverse", domain.JPY, domain.USD, big.NewRat(1, 150), nil},
+		{"Crossed", domain.JPY, domain.BTC, big.NewRat(1, 150*62500), nil},
+		{"Missing", domain.EUR, domain.USD, nil, domain.ErrUnsupportedCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := table.Rate(tt.from, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Rate() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.want != nil && got.Cmp(tt.want) != 0 {
+				t.Errorf("Rate() = %s, want %s", got.RatString(), tt.want.RatString())
+			}
+		})
+	}
+}
+
+func TestStaticRateTable_SetRejectsInvalidRates(t *testing.T) {
+	table := adapter.NewStaticRateTable()
+	for _, rate := range []string{"", "abc", "0", "-1"} {
+		if err := table.Set(domain.USD, domain.EUR, rate); err == nil {
+			t.Errorf("Set(%q) expected error", rate)
+		}
+	}
+	if err := table.Set(domain.USD, domain.EUR, "0.92"); err != nil {
+		t.Errorf("Set(0.92) error = %v", err)
+	}
+}
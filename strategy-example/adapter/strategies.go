@@ -1,83 +1,220 @@
 package adapter
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strategy-example/domain"
+	"strings"
+	"time"
 )
 
 // Ensure that the strategies implement the relevant interfaces.
 var (
-	_ domain.PaymentMethod  = (*CreditCardStrategy)(nil)
-	_ domain.PaymentMethod  = (*PayPalStrategy)(nil)
-	_ domain.PaymentMethod  = (*BitcoinStrategy)(nil)
-	_ domain.ShippingMethod = (*StandardShippingStrategy)(nil)
-	_ domain.ShippingMethod = (*ExpressShippingStrategy)(nil)
+	_ domain.PaymentMethod      = (*CreditCardStrategy)(nil)
+	_ domain.PaymentMethod      = (*PayPalStrategy)(nil)
+	_ domain.PaymentMethod      = (*BitcoinStrategy)(nil)
+	_ domain.CurrencyRestricted = (*CreditCardStrategy)(nil)
+	_ domain.CurrencyRestricted = (*PayPalStrategy)(nil)
+	_ domain.CurrencyRestricted = (*BitcoinStrategy)(nil)
+	_ domain.ShippingMethod     = (*StandardShippingStrategy)(nil)
+	_ domain.ShippingMethod     = (*ExpressShippingStrategy)(nil)
 )
 
+// CardDetails is what the customer enters for a card payment.
+type CardDetails struct {
+	Number   string // Spaces and dashes are allowed
+	ExpMonth int    // 1-12
+	ExpYear  int    // 4 digits, or 2 digits meaning 20xx
+	CVV      string
+}
+
 // CreditCardStrategy implements the PaymentMethod interface for Credit Card payments.
+// The CVV is checked when the strategy is built and is not kept afterwards:
+// card data standards forbid storing it.
 type CreditCardStrategy struct {
 	cardNumber string
-	cvv        string
+	brand      CardBrand
+	expMonth   int
+	expYear    int
+	now        func() time.Time
+	ledger     *ledger
+	logger     domain.Logger
 }
 
-// NewCreditCardStrategy builds a CreditCardStrategy.
-func NewCreditCardStrategy(cardNumber, cvv string) *CreditCardStrategy {
+// NewCreditCardStrategy validates the card (Luhn checksum, brand, expiry
+// fields, CVV length for the brand) and builds a CreditCardStrategy.
+// Whether the card has expired is checked on each payment.
+// Payments and refunds are reported to logger with the card number masked.
+func NewCreditCardStrategy(card CardDetails, logger domain.Logger) (*CreditCardStrategy, error) {
+	number := normalizeCardNumber(card.Number)
+	if !luhnValid(number) {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidCardNumber, maskCardNumber(number))
+	}
+	rule, err := detectBrand(number)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCVV(card.CVV, rule); err != nil {
+		return nil, err
+	}
+	month, year, err := validateExpiry(card.ExpMonth, card.ExpYear)
+	if err != nil {
+		return nil, err
+	}
+
 	return &CreditCardStrategy{
-		cardNumber: cardNumber,
-		cvv:        cvv,
+		cardNumber: number,
+		brand:      rule.brand,
+		expMonth:   month,
+		expYear:    year,
+		now:        time.Now,
+		ledger:     newLedger("cc"),
+		logger:     logger,
+	}, nil
+}
+
+// Brand returns the detected card network.
+func (c *CreditCardStrategy) Brand() CardBrand {
+	return c.brand
+}
+
+// SetClock replaces the clock the card expiry is checked against.
+func (c *CreditCardStrategy) SetClock(now func() time.Time) {
+	c.now = now
+}
+
+// Pay charges amount and returns a receipt.
+func (c *CreditCardStrategy) Pay(ctx context.Context, amount domain.Money) (domain.Receipt, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Receipt{}, err
+	}
+	if expired(c.expMonth, c.expYear, c.now()) {
+		return domain.Receipt{}, fmt.Errorf("%w: %02d/%d", domain.ErrCardExpired, c.expMonth, c.expYear)
 	}
+	if err := checkCurrency(amount, c.AcceptedCurrencies()); err != nil {
+		return domain.Receipt{}, err
+	}
+	receipt := c.ledger.issue(amount)
+	c.logger.Log(fmt.Sprintf("Paying %s using Credit Card (%s %s, Transaction: %s)", amount, c.brand, maskCardNumber(c.cardNumber), receipt.TransactionID))
+	return receipt, nil
+}
+
+// AcceptedCurrencies lists the currencies the card network settles in.
+func (c *CreditCardStrategy) AcceptedCurrencies() []domain.Currency {
+	return []domain.Currency{domain.USD, domain.EUR, domain.JPY}
 }
 
-func (c *CreditCardStrategy) Pay(amount float64) error {
-	if c.cardNumber == "" {
-		return errors.New("credit card number is empty")
+// Refund returns the money to the card.
+func (c *CreditCardStrategy) Refund(ctx context.Context, receipt domain.Receipt) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
-	last4 := c.cardNumber
-	if len(last4) > 4 {
-		last4 = last4[len(last4)-4:]
+	if err := c.ledger.refund(receipt); err != nil {
+		return err
 	}
-	fmt.Printf("Paying $%.2f using Credit Card (Last 4: %s)\n", amount, last4)
+	c.logger.Log(fmt.Sprintf("Refunding %s to Credit Card (%s %s, Transaction: %s)", receipt.Amount, c.brand, maskCardNumber(c.cardNumber), receipt.TransactionID))
 	return nil
 }
 
 // PayPalStrategy implements the PaymentMethod interface for PayPal payments.
 type PayPalStrategy struct {
-	email string
+	email  string
+	ledger *ledger
+	logger domain.Logger
 }
 
-// NewPayPalStrategy builds a PayPalStrategy.
-func NewPayPalStrategy(email string) *PayPalStrategy {
+// NewPayPalStrategy validates the account email and builds a PayPalStrategy.
+func NewPayPalStrategy(email string, logger domain.Logger) (*PayPalStrategy, error) {
+	normalized, err := normalizeEmail(email)
+	if err != nil {
+		return nil, err
+	}
 	return &PayPalStrategy{
-		email: email,
+		email:  normalized,
+		ledger: newLedger("pp"),
+		logger: logger,
+	}, nil
+}
+
+// Pay charges amount and returns a receipt.
+func (p *PayPalStrategy) Pay(ctx context.Context, amount domain.Money) (domain.Receipt, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Receipt{}, err
+	}
+	if err := checkCurrency(amount, p.AcceptedCurrencies()); err != nil {
+		return domain.Receipt{}, err
 	}
+	receipt := p.ledger.issue(amount)
+	p.logger.Log(fmt.Sprintf("Paying %s using PayPal (Account: %s, Transaction: %s)", amount, maskEmail(p.email), receipt.TransactionID))
+	return receipt, nil
 }
 
-func (p *PayPalStrategy) Pay(amount float64) error {
-	if p.email == "" {
-		return errors.New("paypal account email is empty")
+// AcceptedCurrencies lists the currencies PayPal accepts.
+func (p *PayPalStrategy) AcceptedCurrencies() []domain.Currency {
+	return []domain.Currency{domain.USD, domain.EUR, domain.JPY}
+}
+
+// Refund returns the money to the PayPal account.
+func (p *PayPalStrategy) Refund(ctx context.Context, receipt domain.Receipt) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
-	fmt.Printf("Paying $%.2f using PayPal (Account: %s)\n", amount, p.email)
+	if err := p.ledger.refund(receipt); err != nil {
+		return err
+	}
+	p.logger.Log(fmt.Sprintf("Refunding %s to PayPal (Account: %s, Transaction: %s)", receipt.Amount, maskEmail(p.email), receipt.TransactionID))
 	return nil
 }
 
 // BitcoinStrategy implements the PaymentMethod interface for Bitcoin payments.
 type BitcoinStrategy struct {
 	walletAddress string
+	ledger        *ledger
+	logger        domain.Logger
 }
 
-// NewBitcoinStrategy builds a BitcoinStrategy.
-func NewBitcoinStrategy(wallet string) *BitcoinStrategy {
+// NewBitcoinStrategy validates the wallet address (format and checksum)
+// and builds a BitcoinStrategy.
+func NewBitcoinStrategy(wallet string, logger domain.Logger) (*BitcoinStrategy, error) {
+	if err := validateWalletAddress(wallet); err != nil {
+		return nil, err
+	}
 	return &BitcoinStrategy{
-		walletAddress: wallet,
+		walletAddress: strings.TrimSpace(wallet),
+		ledger:        newLedger("btc"),
+		logger:        logger,
+	}, nil
+}
+
+// Pay charges amount and returns a receipt.
+func (b *BitcoinStrategy) Pay(ctx context.Context, amount domain.Money) (domain.Receipt, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Receipt{}, err
+	}
+	if err := checkCurrency(amount, b.AcceptedCurrencies()); err != nil {
+		return domain.Receipt{}, err
 	}
+	receipt := b.ledger.issue(amount)
+	b.logger.Log(fmt.Sprintf("Paying %s using Bitcoin (Wallet: %s, Transaction: %s)", amount, maskWallet(b.walletAddress), receipt.TransactionID))
+	return receipt, nil
 }
 
-func (b *BitcoinStrategy) Pay(amount float64) error {
-	if b.walletAddress == "" {
-		return errors.New("bitcoin wallet address is empty")
+// AcceptedCurrencies returns BTC only; other currencies must be converted first.
+func (b *BitcoinStrategy) AcceptedCurrencies() []domain.Currency {
+	return []domain.Currency{domain.BTC}
+}
+
+// Refund sends the amount back to the wallet.
+func (b *BitcoinStrategy) Refund(ctx context.Context, receipt domain.Receipt) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
-	fmt.Printf("Paying $%.2f using Bitcoin (Wallet: %s)\n", amount, b.walletAddress)
+	if err := b.ledger.refund(receipt); err != nil {
+		return err
+	}
+	b.logger.Log(fmt.Sprintf("Refunding %s to Bitcoin (Wallet: %s, Transaction: %s)", receipt.Amount, maskWallet(b.walletAddress), receipt.TransactionID))
 	return nil
 }
 
@@ -85,43 +222,123 @@ func (b *BitcoinStrategy) Pay(amount float64) error {
 type StandardShippingStrategy struct {
 	carrier     string
 	transitDays int
+	tariff      Tariff
+	logger      domain.Logger
 }
 
 // NewStandardShippingStrategy builds a StandardShippingStrategy.
-func NewStandardShippingStrategy(carrier string, transitDays int) *StandardShippingStrategy {
+func NewStandardShippingStrategy(carrier string, transitDays int, tariff Tariff, logger domain.Logger) *StandardShippingStrategy {
 	return &StandardShippingStrategy{
 		carrier:     carrier,
 		transitDays: transitDays,
+		tariff:      tariff,
+		logger:      logger,
+	}
+}
+
+// Quote prices the parcel with the tariff; delivery takes transitDays.
+func (s *StandardShippingStrategy) Quote(ctx context.Context, destination string, weightGrams int) (domain.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Quote{}, err
+	}
+	if err := s.validate(destination); err != nil {
+		return domain.Quote{}, err
 	}
+	cost, err := s.tariff.Cost(weightGrams)
+	if err != nil {
+		return domain.Quote{}, err
+	}
+	return domain.Quote{
+		Carrier: s.carrier,
+		Cost:    cost,
+		ETA:     time.Duration(s.transitDays) * 24 * time.Hour,
+	}, nil
 }
 
-func (s *StandardShippingStrategy) Ship(destination string) error {
+func (s *StandardShippingStrategy) Ship(ctx context.Context, destination string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := s.validate(destination); err != nil {
+		return err
+	}
+	s.logger.Log(fmt.Sprintf("Scheduling standard %s shipping to %s (ETA: %d days)", s.carrier, destination, s.transitDays))
+	return nil
+}
+
+func (s *StandardShippingStrategy) validate(destination string) error {
+	if destination == "" {
+		return domain.ErrInvalidDestination
+	}
 	if s.carrier == "" {
 		return errors.New("shipping carrier is empty")
 	}
 	if s.transitDays <= 0 {
 		return errors.New("invalid transit days")
 	}
-	fmt.Printf("Scheduling standard %s shipping to %s (ETA: %d days)\n", s.carrier, destination, s.transitDays)
 	return nil
 }
 
 // ExpressShippingStrategy implements the ShippingMethod interface for express deliveries.
 type ExpressShippingStrategy struct {
 	carrier string
+	tariff  Tariff
+	logger  domain.Logger
 }
 
 // NewExpressShippingStrategy builds an ExpressShippingStrategy.
-func NewExpressShippingStrategy(carrier string) *ExpressShippingStrategy {
+func NewExpressShippingStrategy(carrier string, tariff Tariff, logger domain.Logger) *ExpressShippingStrategy {
 	return &ExpressShippingStrategy{
 		carrier: carrier,
+		tariff:  tariff,
+		logger:  logger,
 	}
 }
 
-func (e *ExpressShippingStrategy) Ship(destination string) error {
+// Quote prices the parcel with the tariff for next day delivery.
+func (e *ExpressShippingStrategy) Quote(ctx context.Context, destination string, weightGrams int) (domain.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Quote{}, err
+	}
+	if err := e.validate(destination); err != nil {
+		return domain.Quote{}, err
+	}
+	cost, err := e.tariff.Cost(weightGrams)
+	if err != nil {
+		return domain.Quote{}, err
+	}
+	return domain.Quote{
+		Carrier: e.carrier,
+		Cost:    cost,
+		ETA:     24 * time.Hour,
+	}, nil
+}
+
+func (e *ExpressShippingStrategy) Ship(ctx context.Context, destination string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := e.validate(destination); err != nil {
+		return err
+	}
+	e.logger.Log(fmt.Sprintf("Scheduling express %s shipping to %s (Next day delivery)", e.carrier, destination))
+	return nil
+}
+
+func (e *ExpressShippingStrategy) validate(destination string) error {
+	if destination == "" {
+		return domain.ErrInvalidDestination
+	}
 	if e.carrier == "" {
 		return errors.New("shipping carrier is empty")
 	}
-	fmt.Printf("Scheduling express %s shipping to %s (Next day delivery)\n", e.carrier, destination)
 	return nil
 }
+
+// checkCurrency rejects amounts in a currency the strategy cannot charge.
+func checkCurrency(amount domain.Money, accepted []domain.Currency) error {
+	if slices.Contains(accepted, amount.Currency) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s (accepted: %v)", domain.ErrUnsupportedCurrency, amount.Currency, accepted)
+}
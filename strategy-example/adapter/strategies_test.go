@@ -0,0 +1,250 @@
+package adapter_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"strategy-example/adapter"
+	"strategy-example/domain"
+)
+
+// MockLogger records the logged messages
+type MockLogger struct {
+	Messages []string
+}
+
+func (m *MockLogger) Log(message string) {
+	m.Messages = append(m.Messages, message)
+}
+
+// fixedNow is the date the card tests run at, so expiry does not depend on the real clock
+func fixedNow() time.Time {
+	return time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+}
+
+func newCard(t *testing.T) *adapter.CreditCardStrategy {
+	t.Helper()
+	card, err := adapter.NewCreditCardStrategy(adapter.CardDetails{Number: "4111111111111111", ExpMonth: 12, ExpYear: 2030, CVV: "123"}, &MockLogger{})
+	if err != nil {
+		t.Fatalf("NewCreditCardStrategy() error = %v", err)
+	}
+	card.SetClock(fixedNow)
+	return card
+}
+
+func newPayPal(t *testing.T) *adapter.PayPalStrategy {
+	t.Helper()
+	paypal, err := adapter.NewPayPalStrategy("user@example.com", &MockLogger{})
+	if err != nil {
+		t.Fatalf("NewPayPalStrategy() error = %v", err)
+	}
+	return paypal
+}
+
+func newBitcoin(t *testing.T) *adapter.BitcoinStrategy {
+	t.Helper()
+	bitcoin, err := adapter.NewBitcoinStrategy("1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", &MockLogger{})
+	if err != nil {
+		t.Fatalf("NewBitcoinStrategy() error = %v", err)
+	}
+	return bitcoin
+}
+
+func TestPaymentStrategies_PayAndRefund(t *testing.T) {
+	tests := []struct {
+		name   string
+		method domain.PaymentMethod
+		prefix string
+		amount domain.Money
+	}{
+		{"Credit Card", newCard(t), "cc-", domain.MustParseMoney("12.50", domain.USD)},
+		{"PayPal", newPayPal(t), "pp-", domain.MustParseMoney("1500", domain.JPY)},
+		{"Bitcoin", newBitcoin(t), "btc-", domain.MustParseMoney("0.0002", domain.BTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount := tt.amount
+			receipt, err := tt.method.Pay(context.Background(), amount)
+			if err != nil {
+				t.Fatalf("Pay() error = %v", err)
+			}
+			if !strings.HasPrefix(receipt.TransactionID, tt.prefix) {
+				t.Errorf("TransactionID = %q, want prefix %q", receipt.TransactionID, tt.prefix)
+			}
+			if receipt.Amount != amount || receipt.Timestamp.IsZero() {
+				t.Errorf("unexpected receipt %+v", receipt)
+			}
+
+			other, _ := tt.method.Pay(context.Background(), amount)
+			if other.TransactionID == receipt.TransactionID {
+				t.Errorf("transaction IDs must be unique, got %q twice", receipt.TransactionID)
+			}
+
+			if err := tt.method.Refund(context.Background(), receipt); err != nil {
+				t.Fatalf("Refund() error = %v", err)
+			}
+			if err := tt.method.Refund(context.Background(), receipt); !errors.Is(err, domain.ErrAlreadyRefunded) {
+				t.Errorf("second Refund() error = %v, want ErrAlreadyRefunded", err)
+			}
+			if err := tt.method.Refund(context.Background(), domain.Receipt{TransactionID: "unknown"}); !errors.Is(err, domain.ErrUnknownTransaction) {
+				t.Errorf("Refund(unknown) error = %v, want ErrUnknownTransaction", err)
+			}
+		})
+	}
+}
+
+func TestPaymentStrategies_RefundIsPerStrategy(t *testing.T) {
+	card := newCard(t)
+	paypal := newPayPal(t)
+
+	receipt, err := card.Pay(context.Background(), domain.NewMoney(1000, domain.USD))
+	if err != nil {
+		t.Fatalf("Pay() error = %v", err)
+	}
+	// A receipt can only be refunded by the strategy that issued it
+	if err := paypal.Refund(context.Background(), receipt); !errors.Is(err, domain.ErrUnknownTransaction) {
+		t.Errorf("Refund() error = %v, want ErrUnknownTransaction", err)
+	}
+}
+
+func TestPaymentStrategies_RejectUnacceptedCurrency(t *testing.T) {
+	tests := []struct {
+		name   string
+		method domain.PaymentMethod
+		amount domain.Money
+	}{
+		{"Credit Card In BTC", newCard(t), domain.NewMoney(1, domain.BTC)},
+		{"Bitcoin In USD", newBitcoin(t), domain.NewMoney(100, domain.USD)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.method.Pay(context.Background(), tt.amount); !errors.Is(err, domain.ErrUnsupportedCurrency) {
+				t.Errorf("Pay() error = %v, want ErrUnsupportedCurrency", err)
+			}
+		})
+	}
+}
+
+func TestStrategies_HonorCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	card := newCard(t)
+	if _, err := card.Pay(ctx, domain.NewMoney(100, domain.USD)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Pay() error = %v, want Canceled", err)
+	}
+	express := adapter.NewExpressShippingStrategy("DHL", adapter.DefaultExpressTariff, &MockLogger{})
+	if err := express.Ship(ctx, "Tokyo"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Ship() error = %v, want Canceled", err)
+	}
+	if _, err := express.Quote(ctx, "Tokyo", 500); !errors.Is(err, context.Canceled) {
+		t.Errorf("Quote() error = %v, want Canceled", err)
+	}
+}
+
+func TestCreditCardStrategy_RejectsExpiredCard(t *testing.T) {
+	tests := []struct {
+		name    string
+		now     time.Time
+		wantErr error
+	}{
+		{"Long Before Expiry", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), nil},
+		{"Last Moment Of Expiry Month", time.Date(2026, 10, 31, 23, 59, 59, 0, time.UTC), nil},
+		{"First Day After Expiry Month", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), domain.ErrCardExpired},
+		{"Years Later", time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC), domain.ErrCardExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Expiry is only checked against the clock at payment time
+			card, err := adapter.NewCreditCardStrategy(adapter.CardDetails{Number: "4111111111111111", ExpMonth: 10, ExpYear: 2026, CVV: "123"}, &MockLogger{})
+			if err != nil {
+				t.Fatalf("NewCreditCardStrategy() error = %v", err)
+			}
+			card.SetClock(func() time.Time { return tt.now })
+			if _, err := card.Pay(context.Background(), domain.NewMoney(100, domain.USD)); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Pay() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStrategies_LogThroughInjectedLogger(t *testing.T) {
+	logger := &MockLogger{}
+	card, err := adapter.NewCreditCardStrategy(adapter.CardDetails{Number: "4111 1111 1111 1111", ExpMonth: 12, ExpYear: 2030, CVV: "123"}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	card.SetClock(fixedNow)
+	paypal, err := adapter.NewPayPalStrategy("user@example.com", logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	standard := adapter.NewStandardShippingStrategy("Japan Post", 3, adapter.DefaultStandardTariff, logger)
+
+	receipt, err := card.Pay(context.Background(), domain.NewMoney(1250, domain.USD))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := card.Refund(context.Background(), receipt); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := paypal.Pay(context.Background(), domain.NewMoney(500, domain.JPY)); err != nil {
+		t.Fatal(err)
+	}
+	if err := standard.Ship(context.Background(), "Tokyo"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"Paying 12.50 USD using Credit Card (Visa **** 1111, Transaction: " + receipt.TransactionID + ")",
+		"Refunding 12.50 USD to Credit Card (Visa **** 1111, Transaction: " + receipt.TransactionID + ")",
+		"Paying 500 JPY using PayPal (Account: u***@example.com",
+		"Scheduling standard Japan Post shipping to Tokyo (ETA: 3 days)",
+	}
+	if len(logger.Messages) != len(want) {
+		t.Fatalf("logged %d messages, want %d: %q", len(logger.Messages), len(want), logger.Messages)
+	}
+	for i, msg := range logger.Messages {
+		if !strings.HasPrefix(msg, want[i]) {
+			t.Errorf("message %d = %q, want prefix %q", i, msg, want[i])
+		}
+		// Full card numbers and email addresses never reach the log
+		if strings.Contains(msg, "4111111111111111") || strings.Contains(msg, "user@") {
+			t.Errorf("message %d leaks payment details: %q", i, msg)
+		}
+	}
+}
+
+func TestPayPalStrategy_MasksEmail(t *testing.T) {
+	tests := []struct {
+		name  string
+		email string
+		want  string
+	}{
+		{"ASCII", "user@example.com", "u***@example.com"},
+		{"Multi-Byte First Rune", "ürsula@example.com", "ü***@example.com"},
+		{"Japanese Local Part", "山田@example.jp", "山***@example.jp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &MockLogger{}
+			paypal, err := adapter.NewPayPalStrategy(tt.email, logger)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := paypal.Pay(context.Background(), domain.NewMoney(500, domain.JPY)); err != nil {
+				t.Fatal(err)
+			}
+			if len(logger.Messages) != 1 || !strings.Contains(logger.Messages[0], "(Account: "+tt.want+",") {
+				t.Errorf("logged %q, want the account masked as %q", logger.Messages, tt.want)
+			}
+		})
+	}
+}
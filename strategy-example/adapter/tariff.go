@@ -0,0 +1,71 @@
+package adapter
+
+import (
+	"fmt"
+	"strconv"
+
+	"strategy-example/domain"
+)
+
+// Tariff prices a parcel as a base fee plus a fee per started kilogram.
+type Tariff struct {
+	Base           domain.Money
+	PerKg          domain.Money
+	MaxWeightGrams int // 0 means no limit
+}
+
+// Default tariffs used when a shipping strategy is registered without one.
+var (
+	DefaultStandardTariff = Tariff{
+		Base:           domain.MustParseMoney("5.00", domain.USD),
+		PerKg:          domain.MustParseMoney("1.00", domain.USD),
+		MaxWeightGrams: 30000,
+	}
+	DefaultExpressTariff = Tariff{
+		Base:           domain.MustParseMoney("20.00", domain.USD),
+		PerKg:          domain.MustParseMoney("4.00", domain.USD),
+		MaxWeightGrams: 10000,
+	}
+)
+
+// Cost returns the price of a parcel of weightGrams.
+func (t Tariff) Cost(weightGrams int) (domain.Money, error) {
+	if weightGrams <= 0 {
+		return domain.Money{}, fmt.Errorf("%w: %dg", domain.ErrInvalidWeight, weightGrams)
+	}
+	if t.MaxWeightGrams > 0 && weightGrams > t.MaxWeightGrams {
+		return domain.Money{}, fmt.Errorf("%w: %dg exceeds %dg", domain.ErrParcelTooHeavy, weightGrams, t.MaxWeightGrams)
+	}
+	kg := int64((weightGrams + 999) / 1000)
+	return t.Base.Add(domain.NewMoney(t.PerKg.Minor*kg, t.PerKg.Currency))
+}
+
+// tariffFromConfig reads "currency", "base", "per_kg" and "max_weight_g".
+// When "base" is absent the fallback tariff is used.
+func tariffFromConfig(cfg domain.MethodConfig, fallback Tariff) (Tariff, error) {
+	if cfg["base"] == "" {
+		return fallback, nil
+	}
+
+	currency := domain.Currency(cfg["currency"])
+	if currency == "" {
+		currency = fallback.Base.Currency
+	}
+	base, err := domain.ParseMoney(cfg["base"], currency)
+	if err != nil {
+		return Tariff{}, fmt.Errorf("base: %w", err)
+	}
+	perKg := domain.NewMoney(0, currency)
+	if cfg["per_kg"] != "" {
+		if perKg, err = domain.ParseMoney(cfg["per_kg"], currency); err != nil {
+			return Tariff{}, fmt.Errorf("per_kg: %w", err)
+		}
+	}
+	maxWeight := 0
+	if cfg["max_weight_g"] != "" {
+		if maxWeight, err = strconv.Atoi(cfg["max_weight_g"]); err != nil {
+			return Tariff{}, fmt.Errorf("max_weight_g: %w", err)
+		}
+	}
+	return Tariff{Base: base, PerKg: perKg, MaxWeightGrams: maxWeight}, nil
+}
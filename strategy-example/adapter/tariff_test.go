@@ -0,0 +1,84 @@
+package adapter_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"strategy-example/adapter"
+	"strategy-example/domain"
+)
+
+func TestTariff_Cost(t *testing.T) {
+	tariff := adapter.Tariff{
+		Base:           domain.MustParseMoney("5.00", domain.USD),
+		PerKg:          domain.MustParseMoney("1.00", domain.USD),
+		MaxWeightGrams: 5000,
+	}
+
+	tests := []struct {
+		name    string
+		grams   int
+		want    domain.Money
+		wantErr error
+	}{
+		{"Under 1kg", 200, domain.MustParseMoney("6.00", domain.USD), nil},
+		{"Exactly 1kg", 1000, domain.MustParseMoney("6.00", domain.USD), nil},
+		{"Started kg Counts", 1001, domain.MustParseMoney("7.00", domain.USD), nil},
+		{"At Limit", 5000, domain.MustParseMoney("10.00", domain.USD), nil},
+		{"Too Heavy", 5001, domain.Money{}, domain.ErrParcelTooHeavy},
+		{"Zero Weight", 0, domain.Money{}, domain.ErrInvalidWeight},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tariff.Cost(tt.grams)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Cost() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Cost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShippingStrategies_Quote(t *testing.T) {
+	r := adapter.NewDefaultRegistry(&MockLogger{})
+	standard, err := r.Shipping(domain.MethodSelection{
+		Name:   adapter.ShippingStandard,
+		Config: domain.MethodConfig{"carrier": "Japan Post", "transit_days": "3", "currency": "JPY", "base": "800", "per_kg": "150"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	express := adapter.NewExpressShippingStrategy("DHL", adapter.DefaultExpressTariff, &MockLogger{})
+
+	tests := []struct {
+		name     string
+		method   domain.ShippingMethod
+		dest     string
+		grams    int
+		wantCost domain.Money
+		wantETA  time.Duration
+		wantErr  error
+	}{
+		{"Standard From Config", standard, "Tokyo", 2500, domain.NewMoney(1250, domain.JPY), 72 * time.Hour, nil},
+		{"Express Default Tariff", express, "Tokyo", 500, domain.MustParseMoney("24.00", domain.USD), 24 * time.Hour, nil},
+		{"Express Too Heavy", express, "Tokyo", 20000, domain.Money{}, 0, domain.ErrParcelTooHeavy},
+		{"Empty Destination", express, "", 500, domain.Money{}, 0, domain.ErrInvalidDestination},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := tt.method.Quote(context.Background(), tt.dest, tt.grams)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Quote() error = %v, want %v", err, tt.wantErr)
+			}
+			if q.Cost != tt.wantCost || q.ETA != tt.wantETA {
+				t.Errorf("Quote() = %+v, want cost %v ETA %v", q, tt.wantCost, tt.wantETA)
+			}
+		})
+	}
+}
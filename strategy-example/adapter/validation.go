@@ -0,0 +1,293 @@
+package adapter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"strategy-example/domain"
+)
+
+// --- Cards ---
+
+// CardBrand is a card network.
+type CardBrand string
+
+// Supported card brands.
+const (
+	BrandVisa       CardBrand = "Visa"
+	BrandMastercard CardBrand = "Mastercard"
+	BrandAmex       CardBrand = "American Express"
+	BrandJCB        CardBrand = "JCB"
+	BrandDiscover   CardBrand = "Discover"
+)
+
+// brandRule describes how to recognize a brand and what it allows.
+type brandRule struct {
+	brand     CardBrand
+	prefixes  [][2]int // Inclusive ranges of leading digits, e.g. {51, 55}
+	lengths   []int
+	cvvLength int
+}
+
+var brandRules = []brandRule{
+	{BrandAmex, [][2]int{{34, 34}, {37, 37}}, []int{15}, 4},
+	{BrandVisa, [][2]int{{4, 4}}, []int{13, 16, 19}, 3},
+	{BrandMastercard, [][2]int{{51, 55}, {2221, 2720}}, []int{16}, 3},
+	{BrandJCB, [][2]int{{3528, 3589}}, []int{16, 17, 18, 19}, 3},
+	{BrandDiscover, [][2]int{{6011, 6011}, {644, 649}, {65, 65}}, []int{16, 17, 18, 19}, 3},
+}
+
+// normalizeCardNumber removes the spaces and dashes people type between digit groups.
+func normalizeCardNumber(number string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(number))
+}
+
+// luhnValid reports whether digits passes the Luhn checksum.
+func luhnValid(digits string) bool {
+	if len(digits) < 2 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		c := digits[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// detectBrand finds the brand whose prefix and length match digits.
+func detectBrand(digits string) (brandRule, error) {
+	for _, rule := range brandRules {
+		for _, r := range rule.prefixes {
+			width := len(strconv.Itoa(r[0]))
+			if len(digits) < width {
+				continue
+			}
+			lead, _ := strconv.Atoi(digits[:width])
+			if lead < r[0] || lead > r[1] {
+				continue
+			}
+			for _, l := range rule.lengths {
+				if len(digits) == l {
+					return rule, nil
+				}
+			}
+			return brandRule{}, fmt.Errorf("%w: %s numbers have %v digits", domain.ErrInvalidCardNumber, rule.brand, rule.lengths)
+		}
+	}
+	return brandRule{}, domain.ErrUnsupportedCardBrand
+}
+
+// validateCVV checks the CVV length required by the brand.
+func validateCVV(cvv string, rule brandRule) error {
+	if len(cvv) != rule.cvvLength || strings.Trim(cvv, "0123456789") != "" {
+		return fmt.Errorf("%w: %s requires %d digits", domain.ErrInvalidCVV, rule.brand, rule.cvvLength)
+	}
+	return nil
+}
+
+// validateExpiry checks the month and year fields (a 2-digit year means 20xx).
+func validateExpiry(month, year int) (int, int, error) {
+	if year > 0 && year < 100 {
+		year += 2000
+	}
+	if month < 1 || month > 12 || year < 2000 || year > 2099 {
+		return 0, 0, fmt.Errorf("%w: %02d/%d", domain.ErrInvalidExpiry, month, year)
+	}
+	return month, year, nil
+}
+
+// expired reports whether a card valid through month/year has expired at now.
+// Cards are valid until the end of their expiry month.
+func expired(month, year int, now time.Time) bool {
+	firstInvalid := time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, time.UTC)
+	return !now.UTC().Before(firstInvalid)
+}
+
+// maskCardNumber keeps only the last 4 digits, e.g. "**** 4242".
+func maskCardNumber(digits string) string {
+	if len(digits) <= 4 {
+		return "****"
+	}
+	return "**** " + digits[len(digits)-4:]
+}
+
+// --- PayPal ---
+
+// normalizeEmail checks that email is a bare address with a dotted domain
+// and lowercases the domain.
+func normalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || addr.Name != "" {
+		return "", fmt.Errorf("%w: %q", domain.ErrInvalidEmail, email)
+	}
+	local, host, _ := strings.Cut(email, "@")
+	if !strings.Contains(host, ".") || strings.HasPrefix(host, ".") || strings.HasSuffix(host, ".") {
+		return "", fmt.Errorf("%w: %q", domain.ErrInvalidEmail, email)
+	}
+	return local + "@" + strings.ToLower(host), nil
+}
+
+// maskEmail keeps the first character of the local part, e.g. "u***@example.com".
+func maskEmail(email string) string {
+	local, host, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return "***"
+	}
+	first, _ := utf8.DecodeRuneInString(local)
+	return string(first) + "***@" + host
+}
+
+// --- Bitcoin ---
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// validateWalletAddress accepts mainnet legacy/P2SH (Base58Check, "1..." or "3...")
+// and SegWit (bech32 "bc1q...", bech32m "bc1p...") addresses, verifying their checksums.
+func validateWalletAddress(address string) error {
+	address = strings.TrimSpace(address)
+	var ok bool
+	switch {
+	case strings.HasPrefix(strings.ToLower(address), "bc1"):
+		ok = validBech32Address(address)
+	case strings.HasPrefix(address, "1"), strings.HasPrefix(address, "3"):
+		ok = validBase58Address(address)
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidWalletAddress, maskWallet(address))
+	}
+	return nil
+}
+
+func validBase58Address(address string) bool {
+	if len(address) < 26 || len(address) > 35 {
+		return false
+	}
+	n := new(big.Int)
+	for _, c := range address {
+		i := strings.IndexRune(base58Alphabet, c)
+		if i < 0 {
+			return false
+		}
+		n.Mul(n, big.NewInt(58))
+		n.Add(n, big.NewInt(int64(i)))
+	}
+	// Each leading '1' stands for a zero byte
+	zeros := len(address) - len(strings.TrimLeft(address, "1"))
+	decoded := append(make([]byte, zeros), n.Bytes()...)
+	if len(decoded) != 25 {
+		return false
+	}
+	version := decoded[0]
+	if version != 0x00 && version != 0x05 {
+		return false
+	}
+	first := sha256.Sum256(decoded[:21])
+	second := sha256.Sum256(first[:])
+	return bytes.Equal(second[:4], decoded[21:])
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// Checksum constants of BIP 173 (bech32) and BIP 350 (bech32m).
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+func validBech32Address(address string) bool {
+	if len(address) < 14 || len(address) > 74 {
+		return false
+	}
+	// Mixed case is not allowed
+	lower := strings.ToLower(address)
+	if address != lower && address != strings.ToUpper(address) {
+		return false
+	}
+	sep := strings.LastIndexByte(lower, '1')
+	hrp, data := lower[:sep], lower[sep+1:]
+	if hrp != "bc" || len(data) < 7 {
+		return false
+	}
+
+	values := make([]byte, len(data))
+	for i := range data {
+		v := strings.IndexByte(bech32Charset, data[i])
+		if v < 0 {
+			return false
+		}
+		values[i] = byte(v)
+	}
+
+	witnessVersion := values[0]
+	want := bech32Const
+	if witnessVersion > 0 {
+		want = bech32mConst
+	}
+	if witnessVersion > 16 || bech32Polymod(append(hrpExpand(hrp), values...)) != want {
+		return false
+	}
+
+	// The witness program is 2 to 40 bytes (v0: exactly 20 or 32)
+	programBits := (len(values) - 1 - 6) * 5
+	programLen := programBits / 8
+	if programLen < 2 || programLen > 40 {
+		return false
+	}
+	return witnessVersion != 0 || programLen == 20 || programLen == 32
+}
+
+func hrpExpand(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for i := range hrp {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := range hrp {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+func bech32Polymod(values []byte) int {
+	generator := [5]int{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := 1
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ int(v)
+		for i := range 5 {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+// maskWallet keeps the first and last 4 characters, e.g. "1A1z…vfNa".
+func maskWallet(address string) string {
+	if len(address) <= 8 {
+		return "****"
+	}
+	return address[:4] + "…" + address[len(address)-4:]
+}
@@ -0,0 +1,106 @@
+package adapter_test
+
+import (
+	"errors"
+	"testing"
+
+	"strategy-example/adapter"
+	"strategy-example/domain"
+)
+
+func TestNewCreditCardStrategy_Validation(t *testing.T) {
+	tests := []struct {
+		name      string
+		card      adapter.CardDetails
+		wantBrand adapter.CardBrand
+		wantErr   error
+	}{
+		{"Visa", adapter.CardDetails{Number: "4111111111111111", ExpMonth: 12, ExpYear: 2030, CVV: "123"}, adapter.BrandVisa, nil},
+		{"Visa With Separators", adapter.CardDetails{Number: "4242 4242-4242 4242", ExpMonth: 12, ExpYear: 30, CVV: "123"}, adapter.BrandVisa, nil},
+		{"Mastercard", adapter.CardDetails{Number: "5555555555554444", ExpMonth: 1, ExpYear: 2031, CVV: "123"}, adapter.BrandMastercard, nil},
+		{"Mastercard 2-Series", adapter.CardDetails{Number: "2223003122003222", ExpMonth: 1, ExpYear: 2031, CVV: "123"}, adapter.BrandMastercard, nil},
+		{"Amex", adapter.CardDetails{Number: "378282246310005", ExpMonth: 6, ExpYear: 2029, CVV: "1234"}, adapter.BrandAmex, nil},
+		{"JCB", adapter.CardDetails{Number: "3530111333300000", ExpMonth: 6, ExpYear: 2029, CVV: "123"}, adapter.BrandJCB, nil},
+		{"Discover", adapter.CardDetails{Number: "6011111111111117", ExpMonth: 6, ExpYear: 2029, CVV: "123"}, adapter.BrandDiscover, nil},
+		{"Luhn Failure", adapter.CardDetails{Number: "4111111111111112", ExpMonth: 12, ExpYear: 2030, CVV: "123"}, "", domain.ErrInvalidCardNumber},
+		{"Letters", adapter.CardDetails{Number: "4111-abcd-1111-1111", ExpMonth: 12, ExpYear: 2030, CVV: "123"}, "", domain.ErrInvalidCardNumber},
+		{"Empty", adapter.CardDetails{ExpMonth: 12, ExpYear: 2030, CVV: "123"}, "", domain.ErrInvalidCardNumber},
+		{"Unknown Brand", adapter.CardDetails{Number: "9999999999999995", ExpMonth: 12, ExpYear: 2030, CVV: "123"}, "", domain.ErrUnsupportedCardBrand},
+		{"Wrong Length For Brand", adapter.CardDetails{Number: "41111111111111111113", ExpMonth: 12, ExpYear: 2030, CVV: "123"}, "", domain.ErrInvalidCardNumber},
+		{"Amex With 3-Digit CVV", adapter.CardDetails{Number: "378282246310005", ExpMonth: 6, ExpYear: 2029, CVV: "123"}, "", domain.ErrInvalidCVV},
+		{"Visa With 4-Digit CVV", adapter.CardDetails{Number: "4111111111111111", ExpMonth: 12, ExpYear: 2030, CVV: "1234"}, "", domain.ErrInvalidCVV},
+		{"Non-Numeric CVV", adapter.CardDetails{Number: "4111111111111111", ExpMonth: 12, ExpYear: 2030, CVV: "12a"}, "", domain.ErrInvalidCVV},
+		{"Month Out Of Range", adapter.CardDetails{Number: "4111111111111111", ExpMonth: 13, ExpYear: 2030, CVV: "123"}, "", domain.ErrInvalidExpiry},
+		{"Missing Year", adapter.CardDetails{Number: "4111111111111111", ExpMonth: 12, CVV: "123"}, "", domain.ErrInvalidExpiry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card, err := adapter.NewCreditCardStrategy(tt.card, &MockLogger{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewCreditCardStrategy() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if card != nil {
+					t.Errorf("expected no strategy, got %+v", card)
+				}
+				return
+			}
+			if card.Brand() != tt.wantBrand {
+				t.Errorf("Brand() = %q, want %q", card.Brand(), tt.wantBrand)
+			}
+		})
+	}
+}
+
+func TestNewPayPalStrategy_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		email   string
+		wantErr error
+	}{
+		{"Valid", "user@example.com", nil},
+		{"Surrounding Spaces", "  User.Name+shop@Example.CO.JP ", nil},
+		{"Empty", "", domain.ErrInvalidEmail},
+		{"No At Sign", "user.example.com", domain.ErrInvalidEmail},
+		{"No Dotted Domain", "user@localhost", domain.ErrInvalidEmail},
+		{"Display Name", "User <user@example.com>", domain.ErrInvalidEmail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := adapter.NewPayPalStrategy(tt.email, &MockLogger{}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewPayPalStrategy(%q) error = %v, want %v", tt.email, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewBitcoinStrategy_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		wallet  string
+		wantErr error
+	}{
+		{"P2PKH", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", nil},
+		{"P2SH", "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", nil},
+		{"Bech32 P2WPKH", "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", nil},
+		{"Bech32 Upper Case", "BC1QAR0SRRR7XFKVY5L643LYDNW9RE59GTZZWF5MDQ", nil},
+		{"Bech32m Taproot", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", nil},
+		{"Empty", "", domain.ErrInvalidWalletAddress},
+		{"Truncated", "1A1z", domain.ErrInvalidWalletAddress},
+		{"Bad Base58 Checksum", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb", domain.ErrInvalidWalletAddress},
+		{"Base58 Invalid Character", "1A1zP1eP5QGefi2DMPTfTL5SLmv7Divf0a", domain.ErrInvalidWalletAddress},
+		{"Bad Bech32 Checksum", "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdp", domain.ErrInvalidWalletAddress},
+		{"Testnet", "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx", domain.ErrInvalidWalletAddress},
+		{"Mixed Case", "bc1QAR0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", domain.ErrInvalidWalletAddress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := adapter.NewBitcoinStrategy(tt.wallet, &MockLogger{}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewBitcoinStrategy(%q) error = %v, want %v", tt.wallet, err, tt.wantErr)
+			}
+		})
+	}
+}
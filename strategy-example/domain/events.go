@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// CheckoutEventType names a step of the checkout that an EventSink is told about.
+type CheckoutEventType string
+
+// Checkout events, in the order they can occur.
+const (
+	EventPaymentStarted    CheckoutEventType = "payment_started"
+	EventPaymentSucceeded  CheckoutEventType = "payment_succeeded"
+	EventPaymentFailed     CheckoutEventType = "payment_failed"
+	EventShipmentScheduled CheckoutEventType = "shipment_scheduled"
+	EventShipmentFailed    CheckoutEventType = "shipment_failed"
+	EventPaymentRefunded   CheckoutEventType = "payment_refunded"
+	EventRefundFailed      CheckoutEventType = "refund_failed" // The customer is still charged
+)
+
+// CheckoutEvent is a structured record of something that happened during a checkout.
+// Amount is what is charged, after any currency conversion.
+// TransactionID and Method are set once the payment went through,
+// and Err is set for the failure events.
+type CheckoutEvent struct {
+	Type          CheckoutEventType
+	OrderID       string
+	Amount        Money
+	Destination   string
+	TransactionID string
+	Method        string
+	Err           error
+	Time          time.Time
+}
+
+// EventSink receives checkout events, e.g. to write an audit log or feed metrics.
+// Publish must not block the checkout for long; a sink cannot fail the order.
+type EventSink interface {
+	Publish(event CheckoutEvent)
+}
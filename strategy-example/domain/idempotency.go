@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+// Idempotency errors
+var (
+	// ErrOrderInProgress is returned when the same order ID is being processed by another call.
+	ErrOrderInProgress = errors.New("order is already being processed")
+	// ErrIdempotencyKeyReused is returned when an order ID is reused for a different order.
+	ErrIdempotencyKeyReused = errors.New("order ID was used for a different order")
+	// ErrChargeOutstanding is returned for an order that failed after the customer
+	// was charged and could not be refunded. Retrying it would charge again.
+	ErrChargeOutstanding = errors.New("order failed with a charge that was not refunded")
+)
+
+// IdempotencyRecord is what the store remembers about one order ID.
+type IdempotencyRecord struct {
+	Key         string
+	Fingerprint string // Summary of the order, to detect a key reused for another order
+	Done        bool   // False while the order is being processed
+	Failed      bool   // The order failed but Receipt was charged and not refunded
+	Receipt     Receipt
+}
+
+// IdempotencyStore remembers processed orders so that a retried request
+// gets the original result instead of charging the customer again.
+type IdempotencyStore interface {
+	// Reserve claims key for a new order. If the key is already known it
+	// returns the existing record and reserved == false.
+	Reserve(ctx context.Context, key, fingerprint string) (rec IdempotencyRecord, reserved bool, err error)
+	// Complete stores the receipt of a reserved order.
+	Complete(ctx context.Context, key string, receipt Receipt) error
+	// Fail records that a reserved order failed while receipt is still charged.
+	// The key stays taken so the order is not charged again.
+	Fail(ctx context.Context, key string, receipt Receipt) error
+	// Release forgets a reserved order that failed, so it may be retried.
+	Release(ctx context.Context, key string) error
+}
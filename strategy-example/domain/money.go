@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 style currency code.
+type Currency string
+
+// Supported currencies.
+const (
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+	JPY Currency = "JPY"
+	BTC Currency = "BTC"
+)
+
+// minorDigits is the number of decimal places of each currency's minor unit.
+var minorDigits = map[Currency]int{
+	USD: 2,
+	EUR: 2,
+	JPY: 0,
+	BTC: 8, // Satoshi
+}
+
+// Money errors
+var (
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrCurrencyMismatch    = errors.New("currency mismatch")
+	ErrInvalidMoney        = errors.New("invalid money format")
+)
+
+// Digits returns the number of decimal places of the currency's minor unit.
+func (c Currency) Digits() (int, error) {
+	d, ok := minorDigits[c]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnsupportedCurrency, string(c))
+	}
+	return d, nil
+}
+
+// Money is an amount in the currency's minor unit (cents, yen, satoshi...).
+// Integer arithmetic avoids the rounding errors of float64.
+type Money struct {
+	Minor    int64
+	Currency Currency
+}
+
+// NewMoney builds Money from an amount in minor units.
+func NewMoney(minor int64, currency Currency) Money {
+	return Money{Minor: minor, Currency: currency}
+}
+
+var decimalPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// ParseMoney parses a decimal string such as "100.50" in the given currency.
+// More decimal places than the currency allows are rejected.
+func ParseMoney(s string, currency Currency) (Money, error) {
+	digits, err := currency.Digits()
+	if err != nil {
+		return Money{}, err
+	}
+
+	s = strings.TrimSpace(s)
+	if !decimalPattern.MatchString(s) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	if len(frac) > digits {
+		return Money{}, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidMoney, s, digits)
+	}
+	frac += strings.Repeat("0", digits-len(frac))
+
+	minor, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+	return NewMoney(minor, currency), nil
+}
+
+// MustParseMoney is like ParseMoney but panics on error. Use it for constants.
+func MustParseMoney(s string, currency Currency) Money {
+	m, err := ParseMoney(s, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// IsPositive reports whether the amount is greater than zero.
+func (m Money) IsPositive() bool {
+	return m.Minor > 0
+}
+
+// Add returns m + other. Both must be in the same currency.
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return NewMoney(m.Minor+other.Minor, m.Currency), nil
+}
+
+// String formats the amount with the currency's decimal places, e.g. "100.50 USD".
+func (m Money) String() string {
+	digits, err := m.Currency.Digits()
+	if err != nil || digits == 0 {
+		return fmt.Sprintf("%d %s", m.Minor, m.Currency)
+	}
+
+	sign, minor := "", m.Minor
+	if minor < 0 {
+		sign, minor = "-", -minor
+	}
+	s := fmt.Sprintf("%0*d", digits+1, minor)
+	return fmt.Sprintf("%s%s.%s %s", sign, s[:len(s)-digits], s[len(s)-digits:], m.Currency)
+}
+
+// ExchangeRateProvider returns how many units of `to` one unit of `from` buys.
+type ExchangeRateProvider interface {
+	Rate(from, to Currency) (*big.Rat, error)
+}
+
+// CurrencyRestricted is implemented by payment methods that only accept some currencies.
+// The processor converts the order amount into the first accepted currency when needed.
+type CurrencyRestricted interface {
+	AcceptedCurrencies() []Currency
+}
+
+// Convert converts m into the `to` currency, rounding half away from zero to the minor unit.
+func Convert(m Money, to Currency, rates ExchangeRateProvider) (Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+	fromDigits, err := m.Currency.Digits()
+	if err != nil {
+		return Money{}, err
+	}
+	toDigits, err := to.Digits()
+	if err != nil {
+		return Money{}, err
+	}
+	rate, err := rates.Rate(m.Currency, to)
+	if err != nil {
+		return Money{}, err
+	}
+
+	// minor_to = minor_from * rate * 10^(toDigits - fromDigits)
+	v := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Minor), rate)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(toDigits-fromDigits))), nil))
+	if toDigits >= fromDigits {
+		v.Mul(v, scale)
+	} else {
+		v.Quo(v, scale)
+	}
+
+	minor, ok := roundHalfAwayFromZero(v)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %s in %s overflows", ErrInvalidMoney, m, to)
+	}
+	return NewMoney(minor, to), nil
+}
+
+func roundHalfAwayFromZero(v *big.Rat) (int64, bool) {
+	num := new(big.Int).Abs(v.Num())
+	q, r := new(big.Int).QuoRem(num, v.Denom(), new(big.Int))
+	if r.Lsh(r, 1).Cmp(v.Denom()) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if v.Sign() < 0 {
+		q.Neg(q)
+	}
+	return q.Int64(), q.IsInt64()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
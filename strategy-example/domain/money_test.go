@@ -0,0 +1,108 @@
+package domain_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"strategy-example/domain"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input    string
+		currency domain.Currency
+		want     domain.Money
+		wantErr  error
+	}{
+		{"100.50", domain.USD, domain.NewMoney(10050, domain.USD), nil},
+		{"100", domain.USD, domain.NewMoney(10000, domain.USD), nil},
+		{"0.1", domain.EUR, domain.NewMoney(10, domain.EUR), nil},
+		{"-2.5", domain.USD, domain.NewMoney(-250, domain.USD), nil},
+		{"1500", domain.JPY, domain.NewMoney(1500, domain.JPY), nil},
+		{"0.00000001", domain.BTC, domain.NewMoney(1, domain.BTC), nil},
+		{"1.5", domain.JPY, domain.Money{}, domain.ErrInvalidMoney},
+		{"1.005", domain.USD, domain.Money{}, domain.ErrInvalidMoney},
+		{"", domain.USD, domain.Money{}, domain.ErrInvalidMoney},
+		{"1.-5", domain.USD, domain.Money{}, domain.ErrInvalidMoney},
+		{"abc", domain.USD, domain.Money{}, domain.ErrInvalidMoney},
+		{"1", domain.Currency("XXX"), domain.Money{}, domain.ErrUnsupportedCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input+" "+string(tt.currency), func(t *testing.T) {
+			got, err := domain.ParseMoney(tt.input, tt.currency)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseMoney() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMoney() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	tests := []struct {
+		money domain.Money
+		want  string
+	}{
+		{domain.NewMoney(10050, domain.USD), "100.50 USD"},
+		{domain.NewMoney(5, domain.EUR), "0.05 EUR"},
+		{domain.NewMoney(-250, domain.USD), "-2.50 USD"},
+		{domain.NewMoney(1500, domain.JPY), "1500 JPY"},
+		{domain.NewMoney(80000, domain.BTC), "0.00080000 BTC"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestMoney_Add(t *testing.T) {
+	sum, err := domain.NewMoney(10, domain.USD).Add(domain.NewMoney(20, domain.USD))
+	if err != nil || sum != domain.NewMoney(30, domain.USD) {
+		t.Errorf("Add() = %+v, %v", sum, err)
+	}
+	if _, err := domain.NewMoney(10, domain.USD).Add(domain.NewMoney(10, domain.JPY)); !errors.Is(err, domain.ErrCurrencyMismatch) {
+		t.Errorf("Add() error = %v, want ErrCurrencyMismatch", err)
+	}
+}
+
+// fixedRate returns the same rate for every pair.
+type fixedRate struct{ rate *big.Rat }
+
+func (f fixedRate) Rate(from, to domain.Currency) (*big.Rat, error) {
+	return f.rate, nil
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name string
+		from domain.Money
+		to   domain.Currency
+		rate *big.Rat
+		want domain.Money
+	}{
+		{"Same Currency", domain.NewMoney(100, domain.USD), domain.USD, nil, domain.NewMoney(100, domain.USD)},
+		{"USD To JPY", domain.NewMoney(1050, domain.USD), domain.JPY, big.NewRat(150, 1), domain.NewMoney(1575, domain.JPY)},
+		{"JPY To USD", domain.NewMoney(1000, domain.JPY), domain.USD, big.NewRat(1, 150), domain.NewMoney(667, domain.USD)},
+		{"USD To BTC", domain.NewMoney(5000, domain.USD), domain.BTC, big.NewRat(1, 62500), domain.NewMoney(80000, domain.BTC)},
+		{"Half Up", domain.NewMoney(1, domain.EUR), domain.USD, big.NewRat(1, 2), domain.NewMoney(1, domain.USD)},
+		{"Negative Half", domain.NewMoney(-1, domain.EUR), domain.USD, big.NewRat(1, 2), domain.NewMoney(-1, domain.USD)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := domain.Convert(tt.from, tt.to, fixedRate{tt.rate})
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Convert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,16 +1,69 @@
 package domain
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
 
 // PaymentMethod defines the interface that all payment strategies must implement.
 // This is the abstraction layer in Clean Architecture.
+// Implementations should give up when ctx is done and classify failures:
+// Retryable only when nothing was charged, OutcomeUnknown when the charge
+// may have gone through (e.g. the gateway timed out after receiving the request).
 type PaymentMethod interface {
-	Pay(amount float64) error
+	// Pay charges amount and returns the receipt of the transaction.
+	// Pay is retried after a Retryable error. After an OutcomeUnknown error it
+	// is retried only if the method implements IdempotentPayment and reports true.
+	Pay(ctx context.Context, amount Money) (Receipt, error)
+	// Refund reverses a transaction previously returned by Pay.
+	Refund(ctx context.Context, receipt Receipt) error
+}
+
+// IdempotentPayment is implemented by payment methods that can guarantee a
+// repeated Pay charges at most once, e.g. by sending the provider the same
+// idempotency key with every attempt.
+type IdempotentPayment interface {
+	ChargesIdempotently() bool
+}
+
+// Receipt is the proof of a successful payment.
+// Amount is what was actually charged, which may be in a different currency
+// than the order when the payment method required a conversion.
+// Method names the strategy that charged when a composite chose among several.
+type Receipt struct {
+	TransactionID string
+	Amount        Money
+	Timestamp     time.Time
+	Method        string
+}
+
+// RefundError is returned when a payment had to be reversed but the refund failed.
+// The customer is still charged for Receipt.
+type RefundError struct {
+	Receipt Receipt
+	Err     error
 }
 
+func (e *RefundError) Error() string {
+	return fmt.Sprintf("refund %s: %v", e.Receipt.TransactionID, e.Err)
+}
+
+func (e *RefundError) Unwrap() error { return e.Err }
+
 // ShippingMethod defines the interface that shipping strategies must implement.
 type ShippingMethod interface {
-	Ship(destination string) error
+	// Quote prices a parcel of weightGrams to destination without shipping it.
+	Quote(ctx context.Context, destination string, weightGrams int) (Quote, error)
+	Ship(ctx context.Context, destination string) error
+}
+
+// Quote is a shipping offer for one parcel.
+type Quote struct {
+	Carrier string
+	Cost    Money
+	ETA     time.Duration
 }
 
 // Logger defines the interface for logging.
@@ -19,13 +72,37 @@ type Logger interface {
 }
 
 // OrderContext holds the data required for a full checkout, including payment and shipping.
+// Payment and Shipping optionally name registered strategies; when they are
+// empty the processor uses the strategies it was given.
+// OrderID is the idempotency key: resubmitting the same ID returns the
+// original receipt instead of charging again.
 type OrderContext struct {
-	Amount      float64
+	OrderID     string
+	Amount      Money
 	Destination string
+	Payment     MethodSelection
+	Shipping    MethodSelection
 }
 
 // Custom Errors
 var (
 	ErrInvalidAmount      = errors.New("invalid amount")
 	ErrInvalidDestination = errors.New("invalid destination")
+	ErrUnknownTransaction = errors.New("unknown transaction")
+	ErrAlreadyRefunded    = errors.New("transaction already refunded")
+	ErrInvalidWeight      = errors.New("invalid parcel weight")
+	ErrParcelTooHeavy     = errors.New("parcel too heavy")
+	ErrNoShippingOption   = errors.New("no shipping option available")
+	ErrAllPaymentsFailed  = errors.New("all payment methods failed")
+)
+
+// Payment details errors
+var (
+	ErrInvalidCardNumber    = errors.New("invalid card number")
+	ErrUnsupportedCardBrand = errors.New("unsupported card brand")
+	ErrInvalidExpiry        = errors.New("invalid card expiry")
+	ErrCardExpired          = errors.New("card expired")
+	ErrInvalidCVV           = errors.New("invalid CVV")
+	ErrInvalidEmail         = errors.New("invalid email address")
+	ErrInvalidWalletAddress = errors.New("invalid bitcoin wallet address")
 )
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Method kinds used by UnknownMethodError.
+const (
+	KindPayment  = "payment"
+	KindShipping = "shipping"
+)
+
+// ErrUnknownMethod is matched by every UnknownMethodError via errors.Is.
+var ErrUnknownMethod = errors.New("unknown method")
+
+// MethodConfig holds the settings a factory needs to build a strategy,
+// e.g. {"email": "user@example.com"} for PayPal.
+type MethodConfig map[string]string
+
+// MethodSelection names a registered strategy and the config to build it with.
+type MethodSelection struct {
+	Name   string       `json:"name"`
+	Config MethodConfig `json:"config,omitempty"`
+}
+
+// PaymentFactory builds a PaymentMethod from its config.
+type PaymentFactory func(cfg MethodConfig) (PaymentMethod, error)
+
+// ShippingFactory builds a ShippingMethod from its config.
+type ShippingFactory func(cfg MethodConfig) (ShippingMethod, error)
+
+// UnknownMethodError is returned when a payment or shipping method name
+// has not been registered.
+type UnknownMethodError struct {
+	Kind string
+	Name string
+}
+
+func (e *UnknownMethodError) Error() string {
+	return fmt.Sprintf("unknown %s method %q", e.Kind, e.Name)
+}
+
+// Is lets callers check errors.Is(err, ErrUnknownMethod).
+func (e *UnknownMethodError) Is(target error) bool {
+	return target == ErrUnknownMethod
+}
+
+// CheckoutConfig selects the default payment and shipping methods,
+// typically loaded from a config file.
+type CheckoutConfig struct {
+	Payment  MethodSelection `json:"payment"`
+	Shipping MethodSelection `json:"shipping"`
+}
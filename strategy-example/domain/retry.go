@@ -0,0 +1,81 @@
+package domain
+
+import "errors"
+
+// Sentinels for classifying failures. Check them with errors.Is.
+var (
+	// ErrRetryable marks a transient failure where the call certainly did not
+	// take effect (gateway busy, rate limit...), so it may be made again.
+	ErrRetryable = errors.New("retryable")
+	// ErrOutcomeUnknown marks a failure after which the call may or may not have
+	// taken effect (timeout at the gateway after the request was sent...).
+	// Repeating it is only safe if the call is idempotent. It wins over ErrRetryable.
+	ErrOutcomeUnknown = errors.New("outcome unknown")
+	// ErrPermanent marks a failure that will not go away by retrying
+	// (card declined, invalid address...). It wins over ErrRetryable.
+	ErrPermanent = errors.New("permanent")
+)
+
+// RetryableError wraps an error so that errors.Is(err, ErrRetryable) holds.
+type RetryableError struct {
+	Err error
+}
+
+func (e *RetryableError) Error() string        { return e.Err.Error() }
+func (e *RetryableError) Unwrap() error        { return e.Err }
+func (e *RetryableError) Is(target error) bool { return target == ErrRetryable }
+
+// PermanentError wraps an error so that errors.Is(err, ErrPermanent) holds.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string        { return e.Err.Error() }
+func (e *PermanentError) Unwrap() error        { return e.Err }
+func (e *PermanentError) Is(target error) bool { return target == ErrPermanent }
+
+// OutcomeUnknownError wraps an error so that errors.Is(err, ErrOutcomeUnknown) holds.
+type OutcomeUnknownError struct {
+	Err error
+}
+
+func (e *OutcomeUnknownError) Error() string        { return e.Err.Error() }
+func (e *OutcomeUnknownError) Unwrap() error        { return e.Err }
+func (e *OutcomeUnknownError) Is(target error) bool { return target == ErrOutcomeUnknown }
+
+// Retryable marks err as transient. It returns nil for a nil error.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryableError{Err: err}
+}
+
+// Permanent marks err as final. It returns nil for a nil error.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// OutcomeUnknown marks err as leaving the outcome of the call unknown.
+// It returns nil for a nil error.
+func OutcomeUnknown(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &OutcomeUnknownError{Err: err}
+}
+
+// IsRetryable reports whether err was marked retryable, and neither permanent
+// nor of unknown outcome. Unclassified errors are treated as permanent, so a
+// payment is never charged twice because of an error nobody expected.
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrRetryable) && !errors.Is(err, ErrPermanent) && !errors.Is(err, ErrOutcomeUnknown)
+}
+
+// IsOutcomeUnknown reports whether err was marked as of unknown outcome and not permanent.
+func IsOutcomeUnknown(err error) bool {
+	return errors.Is(err, ErrOutcomeUnknown) && !errors.Is(err, ErrPermanent)
+}
@@ -0,0 +1,68 @@
+package domain_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"strategy-example/domain"
+)
+
+func TestIsRetryable(t *testing.T) {
+	base := errors.New("boom")
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"Nil", nil, false},
+		{"Unclassified", base, false},
+		{"Retryable", domain.Retryable(base), true},
+		{"Wrapped Retryable", fmt.Errorf("pay: %w", domain.Retryable(base)), true},
+		{"Permanent", domain.Permanent(base), false},
+		{"Permanent Wraps Retryable", domain.Permanent(domain.Retryable(base)), false},
+		{"Outcome Unknown", domain.OutcomeUnknown(base), false},
+		{"Outcome Unknown Wraps Retryable", domain.OutcomeUnknown(domain.Retryable(base)), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := domain.IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !errors.Is(domain.Retryable(base), base) || domain.Retryable(base).Error() != "boom" {
+		t.Error("Retryable must keep the wrapped error")
+	}
+	if domain.Retryable(nil) != nil || domain.Permanent(nil) != nil || domain.OutcomeUnknown(nil) != nil {
+		t.Error("wrapping nil must return nil")
+	}
+}
+
+func TestIsOutcomeUnknown(t *testing.T) {
+	base := errors.New("gateway timeout")
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"Nil", nil, false},
+		{"Unclassified", base, false},
+		{"Retryable", domain.Retryable(base), false},
+		{"Outcome Unknown", domain.OutcomeUnknown(base), true},
+		{"Wrapped Outcome Unknown", fmt.Errorf("pay: %w", domain.OutcomeUnknown(base)), true},
+		{"Permanent Wraps Outcome Unknown", domain.Permanent(domain.OutcomeUnknown(base)), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := domain.IsOutcomeUnknown(tt.err); got != tt.want {
+				t.Errorf("IsOutcomeUnknown() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,51 +1,259 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strategy-example/adapter"
 	"strategy-example/domain"
 	"strategy-example/usecase"
+	"time"
 )
 
 func main() {
+	ctx := context.Background()
+
 	// 1. Initialize Strategies and Logger (Adapters)
 	logger := adapter.NewConsoleLogger()
-	creditCard := adapter.NewCreditCardStrategy("1234567812345678", "123")
-	paypal := adapter.NewPayPalStrategy("user@example.com")
-	bitcoin := adapter.NewBitcoinStrategy("1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa")
-	standardShipping := adapter.NewStandardShippingStrategy("Japan Post", 5)
-	expressShipping := adapter.NewExpressShippingStrategy("DHL Express")
+	creditCard := must(adapter.NewCreditCardStrategy(adapter.CardDetails{
+		Number:   "4242 4242 4242 4242",
+		ExpMonth: 12,
+		ExpYear:  2030,
+		CVV:      "123",
+	}, logger))
+	paypal := must(adapter.NewPayPalStrategy("user@example.com", logger))
+	bitcoin := must(adapter.NewBitcoinStrategy("1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", logger))
+	standardShipping := adapter.NewStandardShippingStrategy("Japan Post", 5, adapter.DefaultStandardTariff, logger)
+	expressShipping := adapter.NewExpressShippingStrategy("DHL Express", adapter.DefaultExpressTariff, logger)
 
 	// 2. Initialize Usecase with the default strategy (Credit Card) and Logger
 	processor := usecase.NewPaymentProcessor(creditCard, standardShipping, logger)
 
+	// Exchange rates for payment methods that only accept some currencies (e.g. Bitcoin)
+	rates := adapter.NewStaticRateTable()
+	for _, r := range []struct {
+		from, to domain.Currency
+		rate     string
+	}{
+		{domain.USD, domain.JPY, "150"},
+		{domain.EUR, domain.USD, "1.08"},
+		{domain.BTC, domain.USD, "62500"},
+	} {
+		if err := rates.Set(r.from, r.to, r.rate); err != nil {
+			fmt.Printf("error: %v\n", err)
+		}
+	}
+	processor.SetExchangeRates(rates)
+
 	// 3. Execute Business Logic
 	fmt.Println("Scenario 1: Customer pays with Credit Card")
-	if err := processor.ProcessOrder(domain.OrderContext{
-		Amount:      100.50,
+	checkout(ctx, processor, domain.OrderContext{
+		Amount:      domain.MustParseMoney("100.50", domain.USD),
 		Destination: "Tokyo",
-	}); err != nil {
-		fmt.Printf("error: %v\n", err)
-	}
+	})
 
 	fmt.Println("\nScenario 2: Customer switches to PayPal")
 	// Strategy Pattern allows switching behavior at runtime
 	processor.SetPaymentStrategy(paypal)
 	processor.SetShippingStrategy(expressShipping)
-	if err := processor.ProcessOrder(domain.OrderContext{
-		Amount:      50.00,
+	checkout(ctx, processor, domain.OrderContext{
+		Amount:      domain.MustParseMoney("50.00", domain.USD),
 		Destination: "Osaka",
-	}); err != nil {
-		fmt.Printf("error: %v\n", err)
-	}
+	})
 
-	fmt.Println("\nScenario 3: Customer uses Crypto")
+	fmt.Println("\nScenario 3: Customer uses Crypto (USD is converted to BTC)")
 	processor.SetPaymentStrategy(bitcoin)
 	processor.SetShippingStrategy(standardShipping)
-	if err := processor.ProcessOrder(domain.OrderContext{
-		Amount:      0.05,
+	checkout(ctx, processor, domain.OrderContext{
+		Amount:      domain.MustParseMoney("50.00", domain.USD),
 		Destination: "Kyoto",
+	})
+
+	// Strategies can also be selected by name through the registry
+	registry := adapter.NewDefaultRegistry(logger)
+	processor.SetRegistry(registry)
+
+	fmt.Println("\nScenario 4: Default methods loaded from checkout.json")
+	if cfg, err := adapter.LoadCheckoutConfig("checkout.json"); err != nil {
+		fmt.Printf("error: %v\n", err)
+	} else if err := processor.UseConfig(cfg); err != nil {
+		fmt.Printf("error: %v\n", err)
+	}
+	checkout(ctx, processor, domain.OrderContext{
+		Amount:      domain.MustParseMoney("30.00", domain.EUR),
+		Destination: "Sapporo",
+	})
+
+	fmt.Println("\nScenario 5: The order names its own methods")
+	checkout(ctx, processor, domain.OrderContext{
+		Amount:      domain.MustParseMoney("12000", domain.JPY),
+		Destination: "Fukuoka",
+		Payment: domain.MethodSelection{
+			Name:   adapter.PaymentBitcoin,
+			Config: domain.MethodConfig{"wallet": "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"},
+		},
+		Shipping: domain.MethodSelection{
+			Name:   adapter.ShippingExpress,
+			Config: domain.MethodConfig{"carrier": "FedEx"},
+		},
+	})
+
+	fmt.Println("\nScenario 6: Unknown method name")
+	_, err := processor.ProcessOrder(ctx, domain.OrderContext{
+		Amount:      domain.MustParseMoney("10.00", domain.USD),
+		Destination: "Nagoya",
+		Payment:     domain.MethodSelection{Name: "cash"},
+	})
+	var unknown *domain.UnknownMethodError
+	if errors.As(err, &unknown) {
+		fmt.Printf("error: %v (registered: %v)\n", err, registry.PaymentNames())
+	}
+
+	fmt.Println("\nScenario 7: Shipping fails after payment, so the payment is refunded")
+	checkout(ctx, processor, domain.OrderContext{
+		Amount:      domain.MustParseMoney("42.00", domain.USD),
+		Destination: "Sendai",
+		Shipping:    domain.MethodSelection{Name: adapter.ShippingExpress}, // No carrier configured
+	})
+
+	fmt.Println("\nScenario 8: Pick a shipping method by quote")
+	selector := usecase.NewShippingSelector(domain.USD, rates)
+	if err := selector.AddRegistered(registry, map[string]domain.MethodConfig{
+		adapter.ShippingStandard: {"carrier": "Japan Post", "transit_days": "4", "currency": "JPY", "base": "800", "per_kg": "150"},
+		adapter.ShippingExpress:  {"carrier": "DHL Express"},
+	}); err != nil {
+		fmt.Printf("error: %v\n", err)
+	}
+	for _, pick := range []struct {
+		label  string
+		policy usecase.SelectionPolicy
+	}{
+		{"Cheapest", usecase.Cheapest()},
+		{"Fastest", usecase.Fastest()},
+		{"Cheapest within 2 days", usecase.CheapestWithin(48 * time.Hour)},
+	} {
+		option, err := selector.Select(ctx, "Naha", 2500, pick.policy)
+		if err != nil {
+			fmt.Printf("%s: error: %v\n", pick.label, err)
+			continue
+		}
+		fmt.Printf("%s: %s via %s, %s (= %s), ETA %d days\n", pick.label, option.Name, option.Quote.Carrier, option.Quote.Cost, option.ComparableCost, option.Quote.ETA/(24*time.Hour))
+	}
+	option, err := selector.Select(ctx, "Naha", 2500, usecase.Cheapest())
+	if err == nil {
+		processor.SetShippingStrategy(option.Method)
+		checkout(ctx, processor, domain.OrderContext{
+			Amount:      domain.MustParseMoney("25.00", domain.USD),
+			Destination: "Naha",
+		})
+	}
+
+	fmt.Println("\nScenario 9: A busy gateway is retried with exponential backoff")
+	processor.SetRetryPolicy(usecase.RetryPolicy{MaxAttempts: 4, InitialBackoff: 50 * time.Millisecond, Multiplier: 2})
+	processor.SetTimeouts(usecase.StepTimeouts{Payment: time.Second, Shipping: time.Second, Refund: time.Second})
+	processor.SetPaymentStrategy(&busyGateway{PaymentMethod: creditCard, failures: 2})
+	checkout(ctx, processor, domain.OrderContext{
+		Amount:      domain.MustParseMoney("19.99", domain.USD),
+		Destination: "Kobe",
+	})
+
+	fmt.Println("\nScenario 10: The payment step times out")
+	processor.SetTimeouts(usecase.StepTimeouts{Payment: 120 * time.Millisecond})
+	processor.SetPaymentStrategy(&busyGateway{PaymentMethod: creditCard, failures: 10})
+	checkout(ctx, processor, domain.OrderContext{
+		Amount:      domain.MustParseMoney("19.99", domain.USD),
+		Destination: "Kobe",
+	})
+
+	fmt.Println("\nScenario 11: The client resubmits the same order after a network error")
+	processor.SetTimeouts(usecase.StepTimeouts{})
+	processor.SetPaymentStrategy(creditCard)
+	processor.SetIdempotencyStore(adapter.NewMemoryIdempotencyStore())
+	order := domain.OrderContext{
+		OrderID:     "order-1001",
+		Amount:      domain.MustParseMoney("64.00", domain.USD),
+		Destination: "Nara",
+	}
+	checkout(ctx, processor, order)
+	checkout(ctx, processor, order) // Not charged again
+
+	fmt.Println("\nScenario 12: The card has expired, so the next payment method is tried")
+	fallback := usecase.NewFallbackPayment(rates, logger)
+	expiredCard := must(adapter.NewCreditCardStrategy(adapter.CardDetails{
+		Number:   "4111-1111-1111-1111",
+		ExpMonth: 1,
+		ExpYear:  2020,
+		CVV:      "321",
+	}, logger))
+	fallback.Add(adapter.PaymentCreditCard, expiredCard, nil)
+	fallback.Add(adapter.PaymentPayPal, paypal, nil)
+	fallback.Add(adapter.PaymentBitcoin, bitcoin, nil)
+	processor.SetPaymentStrategy(fallback)
+	if receipt, err := processor.ProcessOrder(ctx, domain.OrderContext{
+		Amount:      domain.MustParseMoney("12.00", domain.USD),
+		Destination: "Kanazawa",
 	}); err != nil {
 		fmt.Printf("error: %v\n", err)
+	} else {
+		fmt.Printf("Paid with %s (Transaction: %s)\n", receipt.Method, receipt.TransactionID)
+	}
+
+	fmt.Println("\nScenario 13: Invalid payment details are rejected before any payment")
+	for _, attempt := range []struct {
+		label string
+		err   error
+	}{
+		{"Mistyped card number", second(adapter.NewCreditCardStrategy(adapter.CardDetails{Number: "4242 4242 4242 4241", ExpMonth: 12, ExpYear: 30, CVV: "123"}, logger))},
+		{"Amex with a 3-digit CVV", second(adapter.NewCreditCardStrategy(adapter.CardDetails{Number: "3782 822463 10005", ExpMonth: 12, ExpYear: 30, CVV: "123"}, logger))},
+		{"Email without a domain", second(adapter.NewPayPalStrategy("user@localhost", logger))},
+		{"Wallet with a typo", second(adapter.NewBitcoinStrategy("1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb", logger))},
+	} {
+		fmt.Printf("%s: %v\n", attempt.label, attempt.err)
+	}
+
+	fmt.Println("\nScenario 14: Checkout events are published to an event sink")
+	processor.SetPaymentStrategy(creditCard)
+	processor.SetEventSink(adapter.NewLogEventSink(logger))
+	checkout(ctx, processor, domain.OrderContext{
+		OrderID:     "order-1002",
+		Amount:      domain.MustParseMoney("8.80", domain.USD),
+		Destination: "Yokohama",
+	})
+}
+
+// must stops the demo when a hard-coded strategy is invalid.
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// second returns only the error of a constructor.
+func second[T any](_ T, err error) error {
+	return err
+}
+
+// busyGateway simulates a payment gateway that is busy for the first few calls.
+type busyGateway struct {
+	domain.PaymentMethod
+	failures int
+}
+
+func (g *busyGateway) Pay(ctx context.Context, amount domain.Money) (domain.Receipt, error) {
+	if g.failures > 0 {
+		g.failures--
+		return domain.Receipt{}, domain.Retryable(errors.New("gateway busy"))
+	}
+	return g.PaymentMethod.Pay(ctx, amount)
+}
+
+// checkout processes one order and prints its receipt.
+func checkout(ctx context.Context, processor *usecase.PaymentProcessor, order domain.OrderContext) {
+	receipt, err := processor.ProcessOrder(ctx, order)
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
 	}
+	fmt.Printf("Receipt: %s %s at %s\n", receipt.TransactionID, receipt.Amount, receipt.Timestamp.Format(time.RFC3339))
 }
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"strategy-example/domain"
+)
+
+// Ensure implementation
+var _ domain.PaymentMethod = (*FallbackPayment)(nil)
+
+// FallbackDecision tells FallbackPayment what to do after a member failed.
+type FallbackDecision int
+
+const (
+	// TryNext moves on to the next payment method.
+	TryNext FallbackDecision = iota
+	// StopFallback gives up without trying the remaining methods,
+	// e.g. when the failure would repeat with any method (fraud block, cancellation).
+	StopFallback
+)
+
+// FailureClassifier decides how to react to one payment method's error.
+type FailureClassifier func(err error) FallbackDecision
+
+// DefaultFailureClassifier stops when the checkout was cancelled or timed out,
+// or when the member may have charged (domain.ErrOutcomeUnknown) so another
+// method could charge twice. It tries the next method for any other failure.
+func DefaultFailureClassifier(err error) FallbackDecision {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, domain.ErrOutcomeUnknown) {
+		return StopFallback
+	}
+	return TryNext
+}
+
+type fallbackMember struct {
+	name     string
+	method   domain.PaymentMethod
+	classify FailureClassifier
+}
+
+// FallbackPayment is a composite PaymentMethod that tries its members in order
+// until one succeeds (e.g. credit card, then PayPal, then Bitcoin).
+// The receipt's Method field names the member that charged, and Refund is
+// routed back to that member.
+//
+// Retries belong to the processor: the chain tries each member once, and its
+// error is retryable only if every member failed with a retryable error.
+// Otherwise a retry of the whole chain would charge again a method that
+// already failed for good, so the error is marked permanent.
+type FallbackPayment struct {
+	members []fallbackMember
+	rates   domain.ExchangeRateProvider
+	logger  domain.Logger
+
+	mu        sync.Mutex
+	chargedBy map[string]domain.PaymentMethod // Transaction ID -> member
+}
+
+// NewFallbackPayment creates an empty chain. rates converts the amount for
+// members that only accept some currencies and may be nil.
+func NewFallbackPayment(rates domain.ExchangeRateProvider, logger domain.Logger) *FallbackPayment {
+	return &FallbackPayment{
+		rates:     rates,
+		logger:    logger,
+		chargedBy: make(map[string]domain.PaymentMethod),
+	}
+}
+
+// Add appends a payment method to the chain. A nil classify uses DefaultFailureClassifier.
+func (f *FallbackPayment) Add(name string, method domain.PaymentMethod, classify FailureClassifier) {
+	if classify == nil {
+		classify = DefaultFailureClassifier
+	}
+	f.members = append(f.members, fallbackMember{name: name, method: method, classify: classify})
+}
+
+// Pay tries each member in turn. If none succeeds, the error wraps
+// domain.ErrAllPaymentsFailed together with every member's failure.
+// It is permanent unless every member's failure was retryable.
+func (f *FallbackPayment) Pay(ctx context.Context, amount domain.Money) (domain.Receipt, error) {
+	if len(f.members) == 0 {
+		return domain.Receipt{}, fmt.Errorf("%w: no payment methods configured", domain.ErrAllPaymentsFailed)
+	}
+
+	var errs []error
+	for i, m := range f.members {
+		receipt, err := f.payWith(ctx, m, amount)
+		if err == nil {
+			f.mu.Lock()
+			f.chargedBy[receipt.TransactionID] = m.method
+			f.mu.Unlock()
+			receipt.Method = m.name
+			return receipt, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", m.name, err))
+		if m.classify(err) == StopFallback {
+			f.logger.Log(fmt.Sprintf("--- Payment via %s failed (%v), not trying other methods ---", m.name, err))
+			break
+		}
+		if i < len(f.members)-1 {
+			f.logger.Log(fmt.Sprintf("--- Payment via %s failed (%v), trying %s ---", m.name, err, f.members[i+1].name))
+		}
+	}
+	err := fmt.Errorf("%w: %w", domain.ErrAllPaymentsFailed, errors.Join(errs...))
+	if slices.ContainsFunc(errs, func(err error) bool { return !domain.IsRetryable(err) }) {
+		return domain.Receipt{}, domain.Permanent(err)
+	}
+	return domain.Receipt{}, err
+}
+
+func (f *FallbackPayment) payWith(ctx context.Context, m fallbackMember, amount domain.Money) (domain.Receipt, error) {
+	charge, err := chargeAmount(m.method, amount, f.rates)
+	if err != nil {
+		return domain.Receipt{}, err
+	}
+	return m.method.Pay(ctx, charge)
+}
+
+// Refund sends the refund to the member that charged the receipt.
+func (f *FallbackPayment) Refund(ctx context.Context, receipt domain.Receipt) error {
+	f.mu.Lock()
+	method, ok := f.chargedBy[receipt.TransactionID]
+	f.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %q", domain.ErrUnknownTransaction, receipt.TransactionID)
+	}
+	return method.Refund(ctx, receipt)
+}
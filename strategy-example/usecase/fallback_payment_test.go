@@ -0,0 +1,216 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"strategy-example/domain"
+	"strategy-example/usecase"
+)
+
+// scriptedPayment fails with err, or succeeds with a receipt named after id.
+func scriptedPayment(id string, err error, calls *[]string) *MockPaymentMethod {
+	return &MockPaymentMethod{
+		PayFunc: func(ctx context.Context, amount domain.Money) (domain.Receipt, error) {
+			*calls = append(*calls, id)
+			if err != nil {
+				return domain.Receipt{}, err
+			}
+			return domain.Receipt{TransactionID: id + "-tx", Amount: amount}, nil
+		},
+		RefundFunc: func(ctx context.Context, receipt domain.Receipt) error {
+			*calls = append(*calls, "refund "+id)
+			return nil
+		},
+	}
+}
+
+func TestFallbackPayment_Pay(t *testing.T) {
+	errDeclined := errors.New("card declined")
+	errFraud := errors.New("fraud suspected")
+	stopOnFraud := func(err error) usecase.FallbackDecision {
+		if errors.Is(err, errFraud) {
+			return usecase.StopFallback
+		}
+		return usecase.TryNext
+	}
+
+	tests := []struct {
+		name       string
+		cardErr    error
+		paypalErr  error
+		bitcoinErr error
+		wantMethod string
+		wantCalls  []string
+		wantErrs   []error // Each must be found in the returned error
+	}{
+		{
+			name:       "First Succeeds",
+			wantMethod: "card",
+			wantCalls:  []string{"card"},
+		},
+		{
+			name:       "Falls Back To Second",
+			cardErr:    errDeclined,
+			wantMethod: "paypal",
+			wantCalls:  []string{"card", "paypal"},
+		},
+		{
+			name:       "All Fail",
+			cardErr:    errDeclined,
+			paypalErr:  errors.New("account locked"),
+			bitcoinErr: errors.New("wallet empty"),
+			wantCalls:  []string{"card", "paypal", "bitcoin"},
+			wantErrs:   []error{domain.ErrAllPaymentsFailed, errDeclined},
+		},
+		{
+			name:      "Classifier Stops The Chain",
+			cardErr:   errFraud,
+			wantCalls: []string{"card"},
+			wantErrs:  []error{domain.ErrAllPaymentsFailed, errFraud},
+		},
+		{
+			name:      "Unknown Outcome Stops By Default",
+			cardErr:   errDeclined,
+			paypalErr: domain.OutcomeUnknown(errors.New("gateway timeout")),
+			wantCalls: []string{"card", "paypal"},
+			wantErrs:  []error{domain.ErrOutcomeUnknown},
+		},
+		{
+			name:      "Cancellation Stops By Default",
+			cardErr:   errDeclined,
+			paypalErr: context.Canceled,
+			wantCalls: []string{"card", "paypal"},
+			wantErrs:  []error{errDeclined, context.Canceled},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			f := usecase.NewFallbackPayment(nil, &MockLogger{})
+			f.Add("card", scriptedPayment("card", tt.cardErr, &calls), stopOnFraud)
+			f.Add("paypal", scriptedPayment("paypal", tt.paypalErr, &calls), nil)
+			f.Add("bitcoin", scriptedPayment("bitcoin", tt.bitcoinErr, &calls), nil)
+
+			receipt, err := f.Pay(context.Background(), domain.NewMoney(100, domain.USD))
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Fatalf("Pay() error = %v", err)
+				}
+				if receipt.Method != tt.wantMethod {
+					t.Errorf("Method = %q, want %q", receipt.Method, tt.wantMethod)
+				}
+				return
+			}
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("Pay() error = %v, want it to wrap %v", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFallbackPayment_RetriedByProcessor(t *testing.T) {
+	errBusy := domain.Retryable(errors.New("gateway busy"))
+
+	tests := []struct {
+		name      string
+		cardErr   error
+		paypalErr error
+		wantCalls []string
+		wantErr   error
+	}{
+		{
+			name:      "All Transient Retries The Chain",
+			cardErr:   errBusy,
+			paypalErr: errBusy,
+			wantCalls: []string{"card", "paypal", "card", "paypal", "card", "paypal"},
+			wantErr:   domain.ErrRetryable,
+		},
+		{
+			// The declined card must not be charged again on retry
+			name:      "Permanent Member Failure Is Not Retried",
+			cardErr:   errors.New("card declined"),
+			paypalErr: errBusy,
+			wantCalls: []string{"card", "paypal"},
+			wantErr:   domain.ErrPermanent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			f := usecase.NewFallbackPayment(nil, &MockLogger{})
+			f.Add("card", scriptedPayment("card", tt.cardErr, &calls), nil)
+			f.Add("paypal", scriptedPayment("paypal", tt.paypalErr, &calls), nil)
+
+			p := usecase.NewPaymentProcessor(f, &MockShippingMethod{}, &MockLogger{})
+			p.SetRetryPolicy(fastRetry)
+			_, err := p.ProcessOrder(context.Background(), domain.OrderContext{Amount: domain.NewMoney(100, domain.USD), Destination: "Tokyo"})
+
+			if !errors.Is(err, tt.wantErr) || !errors.Is(err, domain.ErrAllPaymentsFailed) {
+				t.Errorf("ProcessOrder() error = %v, want ErrAllPaymentsFailed and %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestFallbackPayment_RefundGoesToCharger(t *testing.T) {
+	var calls []string
+	f := usecase.NewFallbackPayment(nil, &MockLogger{})
+	f.Add("card", scriptedPayment("card", errors.New("declined"), &calls), nil)
+	f.Add("paypal", scriptedPayment("paypal", nil, &calls), nil)
+
+	receipt, err := f.Pay(context.Background(), domain.NewMoney(100, domain.USD))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Refund(context.Background(), receipt); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"card", "paypal", "refund paypal"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+	if err := f.Refund(context.Background(), domain.Receipt{TransactionID: "other"}); !errors.Is(err, domain.ErrUnknownTransaction) {
+		t.Errorf("Refund(unknown) error = %v", err)
+	}
+}
+
+func TestFallbackPayment_ConvertsPerMember(t *testing.T) {
+	var charged []domain.Money
+	btcOnly := &MockRestrictedPayment{Accepted: []domain.Currency{domain.BTC}}
+	btcOnly.PayFunc = func(ctx context.Context, amount domain.Money) (domain.Receipt, error) {
+		charged = append(charged, amount)
+		return domain.Receipt{TransactionID: "btc-tx", Amount: amount}, nil
+	}
+
+	f := usecase.NewFallbackPayment(&MockRates{RateValue: big.NewRat(1, 50000)}, &MockLogger{})
+	f.Add("bitcoin", btcOnly, nil)
+
+	receipt, err := f.Pay(context.Background(), domain.NewMoney(5000, domain.USD))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := domain.NewMoney(100000, domain.BTC) // 50.00 USD = 0.001 BTC
+	if receipt.Amount != want || len(charged) != 1 || charged[0] != want {
+		t.Errorf("charged %v (receipt %v), want %v", charged, receipt.Amount, want)
+	}
+}
+
+func TestFallbackPayment_Empty(t *testing.T) {
+	f := usecase.NewFallbackPayment(nil, &MockLogger{})
+	if _, err := f.Pay(context.Background(), domain.NewMoney(100, domain.USD)); !errors.Is(err, domain.ErrAllPaymentsFailed) {
+		t.Errorf("Pay() error = %v, want ErrAllPaymentsFailed", err)
+	}
+}
@@ -0,0 +1,256 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"strategy-example/domain"
+	"strategy-example/usecase"
+)
+
+// MockIdempotencyStore is a map-backed domain.IdempotencyStore.
+type MockIdempotencyStore struct {
+	Records     map[string]domain.IdempotencyRecord
+	CompleteErr error
+	Released    []string
+}
+
+func NewMockIdempotencyStore() *MockIdempotencyStore {
+	return &MockIdempotencyStore{Records: make(map[string]domain.IdempotencyRecord)}
+}
+
+func (m *MockIdempotencyStore) Reserve(ctx context.Context, key, fingerprint string) (domain.IdempotencyRecord, bool, error) {
+	if rec, ok := m.Records[key]; ok {
+		return rec, false, nil
+	}
+	m.Records[key] = domain.IdempotencyRecord{Key: key, Fingerprint: fingerprint}
+	return m.Records[key], true, nil
+}
+
+func (m *MockIdempotencyStore) Complete(ctx context.Context, key string, receipt domain.Receipt) error {
+	if m.CompleteErr != nil {
+		return m.CompleteErr
+	}
+	rec := m.Records[key]
+	rec.Done, rec.Receipt = true, receipt
+	m.Records[key] = rec
+	return nil
+}
+
+func (m *MockIdempotencyStore) Fail(ctx context.Context, key string, receipt domain.Receipt) error {
+	rec := m.Records[key]
+	rec.Done, rec.Failed, rec.Receipt = true, true, receipt
+	m.Records[key] = rec
+	return nil
+}
+
+func (m *MockIdempotencyStore) Release(ctx context.Context, key string) error {
+	m.Released = append(m.Released, key)
+	delete(m.Records, key)
+	return nil
+}
+
+// countingPayment issues a new transaction ID per charge.
+func countingPayment(charges *int, payErr *error) *MockPaymentMethod {
+	return &MockPaymentMethod{
+		PayFunc: func(ctx context.Context, amount domain.Money) (domain.Receipt, error) {
+			if *payErr != nil {
+				return domain.Receipt{}, *payErr
+			}
+			*charges++
+			return domain.Receipt{TransactionID: "tx-" + string(rune('0'+*charges)), Amount: amount}, nil
+		},
+	}
+}
+
+func TestPaymentProcessor_Idempotency(t *testing.T) {
+	order := domain.OrderContext{OrderID: "order-1", Amount: domain.NewMoney(500, domain.USD), Destination: "Tokyo"}
+
+	t.Run("Repeated Key Returns Original Receipt", func(t *testing.T) {
+		charges := 0
+		var payErr error
+		p := usecase.NewPaymentProcessor(countingPayment(&charges, &payErr), &MockShippingMethod{}, &MockLogger{})
+		p.SetIdempotencyStore(NewMockIdempotencyStore())
+
+		first, err := p.ProcessOrder(context.Background(), order)
+		if err != nil {
+			t.Fatalf("first ProcessOrder() error = %v", err)
+		}
+		second, err := p.ProcessOrder(context.Background(), order)
+		if err != nil {
+			t.Fatalf("second ProcessOrder() error = %v", err)
+		}
+		if charges != 1 || second != first {
+			t.Errorf("charges = %d, receipts %+v / %+v; want one charge and the same receipt", charges, first, second)
+		}
+
+		// Other orders and orders without an ID are not affected
+		other := order
+		other.OrderID = "order-2"
+		if _, err := p.ProcessOrder(context.Background(), other); err != nil {
+			t.Fatal(err)
+		}
+		anonymous := order
+		anonymous.OrderID = ""
+		for range 2 {
+			if _, err := p.ProcessOrder(context.Background(), anonymous); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if charges != 4 {
+			t.Errorf("charges = %d, want 4", charges)
+		}
+	})
+
+	t.Run("Failed Order Can Be Retried", func(t *testing.T) {
+		charges := 0
+		payErr := errors.New("card declined")
+		store := NewMockIdempotencyStore()
+		p := usecase.NewPaymentProcessor(countingPayment(&charges, &payErr), &MockShippingMethod{}, &MockLogger{})
+		p.SetIdempotencyStore(store)
+
+		if _, err := p.ProcessOrder(context.Background(), order); err == nil {
+			t.Fatal("expected the first attempt to fail")
+		}
+		if len(store.Released) != 1 {
+			t.Errorf("Released = %v, want the key released", store.Released)
+		}
+
+		payErr = nil
+		if _, err := p.ProcessOrder(context.Background(), order); err != nil {
+			t.Fatalf("retry error = %v", err)
+		}
+		if charges != 1 {
+			t.Errorf("charges = %d, want 1", charges)
+		}
+	})
+
+	t.Run("Key Reused For Different Order", func(t *testing.T) {
+		charges := 0
+		var payErr error
+		p := usecase.NewPaymentProcessor(countingPayment(&charges, &payErr), &MockShippingMethod{}, &MockLogger{})
+		p.SetIdempotencyStore(NewMockIdempotencyStore())
+
+		if _, err := p.ProcessOrder(context.Background(), order); err != nil {
+			t.Fatal(err)
+		}
+		changed := order
+		changed.Amount = domain.NewMoney(999, domain.USD)
+		if _, err := p.ProcessOrder(context.Background(), changed); !errors.Is(err, domain.ErrIdempotencyKeyReused) {
+			t.Errorf("error = %v, want ErrIdempotencyKeyReused", err)
+		}
+		if charges != 1 {
+			t.Errorf("charges = %d, want 1", charges)
+		}
+	})
+
+	t.Run("Key Reused With Different Method Config", func(t *testing.T) {
+		charges := 0
+		var payErr error
+		registry := usecase.NewStrategyRegistry()
+		registry.RegisterPayment("card", func(cfg domain.MethodConfig) (domain.PaymentMethod, error) {
+			return countingPayment(&charges, &payErr), nil
+		})
+		registry.RegisterShipping("post", func(cfg domain.MethodConfig) (domain.ShippingMethod, error) {
+			return &MockShippingMethod{}, nil
+		})
+		p := usecase.NewPaymentProcessor(nil, nil, &MockLogger{})
+		p.SetRegistry(registry)
+		p.SetIdempotencyStore(NewMockIdempotencyStore())
+
+		first := order
+		first.Payment = domain.MethodSelection{Name: "card", Config: domain.MethodConfig{"number": "4111111111111111"}}
+		first.Shipping = domain.MethodSelection{Name: "post", Config: domain.MethodConfig{"address": "1-1 Chiyoda"}}
+		if _, err := p.ProcessOrder(context.Background(), first); err != nil {
+			t.Fatal(err)
+		}
+
+		otherCard := first
+		otherCard.Payment.Config = domain.MethodConfig{"number": "5555555555554444"}
+		otherAddress := first
+		otherAddress.Shipping.Config = domain.MethodConfig{"address": "2-2 Shibuya"}
+		for _, changed := range []domain.OrderContext{otherCard, otherAddress} {
+			if _, err := p.ProcessOrder(context.Background(), changed); !errors.Is(err, domain.ErrIdempotencyKeyReused) {
+				t.Errorf("error = %v, want ErrIdempotencyKeyReused", err)
+			}
+		}
+		if charges != 1 {
+			t.Errorf("charges = %d, want 1", charges)
+		}
+	})
+
+	t.Run("Unrefunded Charge Is Not Charged Again", func(t *testing.T) {
+		charges := 0
+		var payErr error
+		payment := countingPayment(&charges, &payErr)
+		refundErr := errors.New("gateway down")
+		payment.RefundFunc = func(ctx context.Context, receipt domain.Receipt) error { return refundErr }
+		shipErr := errors.New("no carrier")
+		shipping := &MockShippingMethod{ShipFunc: func(ctx context.Context, destination string) error { return shipErr }}
+		store := NewMockIdempotencyStore()
+		p := usecase.NewPaymentProcessor(payment, shipping, &MockLogger{})
+		p.SetIdempotencyStore(store)
+
+		_, err := p.ProcessOrder(context.Background(), order)
+		var refundFailure *domain.RefundError
+		if !errors.As(err, &refundFailure) || refundFailure.Receipt.TransactionID != "tx-1" {
+			t.Fatalf("error = %v, want a RefundError for tx-1", err)
+		}
+		if len(store.Released) != 0 {
+			t.Errorf("Released = %v, want the key kept", store.Released)
+		}
+
+		// The client retries after shipping and refunds recovered
+		shipErr, refundErr = nil, nil
+		if _, err := p.ProcessOrder(context.Background(), order); !errors.Is(err, domain.ErrChargeOutstanding) {
+			t.Errorf("retry error = %v, want ErrChargeOutstanding", err)
+		}
+		if charges != 1 {
+			t.Errorf("charges = %d, want 1", charges)
+		}
+	})
+
+	t.Run("Order In Progress", func(t *testing.T) {
+		charges := 0
+		var payErr error
+		// Another call has reserved the key but not completed it yet
+		store := NewMockIdempotencyStore()
+		store.Records[order.OrderID] = domain.IdempotencyRecord{Key: order.OrderID, Fingerprint: fingerprintOf(t, order)}
+		p := usecase.NewPaymentProcessor(countingPayment(&charges, &payErr), &MockShippingMethod{}, &MockLogger{})
+		p.SetIdempotencyStore(store)
+
+		if _, err := p.ProcessOrder(context.Background(), order); !errors.Is(err, domain.ErrOrderInProgress) {
+			t.Errorf("error = %v, want ErrOrderInProgress", err)
+		}
+		if charges != 0 {
+			t.Errorf("charges = %d, want 0", charges)
+		}
+	})
+
+	t.Run("Receipt Returned When Recording Fails", func(t *testing.T) {
+		charges := 0
+		var payErr error
+		store := NewMockIdempotencyStore()
+		store.CompleteErr = errors.New("disk full")
+		p := usecase.NewPaymentProcessor(countingPayment(&charges, &payErr), &MockShippingMethod{}, &MockLogger{})
+		p.SetIdempotencyStore(store)
+
+		receipt, err := p.ProcessOrder(context.Background(), order)
+		if err == nil || receipt.TransactionID == "" {
+			t.Errorf("ProcessOrder() = %+v, %v; want the receipt and an error", receipt, err)
+		}
+	})
+}
+
+// fingerprintOf captures the fingerprint the processor stores for order.
+func fingerprintOf(t *testing.T, order domain.OrderContext) string {
+	t.Helper()
+	store := NewMockIdempotencyStore()
+	p := usecase.NewPaymentProcessor(&MockPaymentMethod{}, &MockShippingMethod{}, &MockLogger{})
+	p.SetIdempotencyStore(store)
+	if _, err := p.ProcessOrder(context.Background(), order); err != nil {
+		t.Fatal(err)
+	}
+	return store.Records[order.OrderID].Fingerprint
+}
@@ -1,7 +1,14 @@
 package usecase
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"slices"
+	"time"
+
 	"strategy-example/domain"
 )
 
@@ -11,14 +18,24 @@ type PaymentProcessor struct {
 	payment  domain.PaymentMethod
 	shipping domain.ShippingMethod
 	logger   domain.Logger
+	registry *StrategyRegistry
+	rates    domain.ExchangeRateProvider
+	retry    RetryPolicy
+	timeouts StepTimeouts
+	orders   domain.IdempotencyStore
+	events   domain.EventSink
 }
 
+// ErrNoRegistry is returned when a method is selected by name but no registry is set.
+var ErrNoRegistry = errors.New("strategy registry is not set")
+
 // NewPaymentProcessor creates a new processor with an initial payment and shipping strategy.
 func NewPaymentProcessor(payment domain.PaymentMethod, shipping domain.ShippingMethod, logger domain.Logger) *PaymentProcessor {
 	return &PaymentProcessor{
 		payment:  payment,
 		shipping: shipping,
 		logger:   logger,
+		retry:    DefaultRetryPolicy(),
 	}
 }
 
@@ -32,31 +49,257 @@ func (p *PaymentProcessor) SetShippingStrategy(shipping domain.ShippingMethod) {
 	p.shipping = shipping
 }
 
-// ProcessOrder executes the business logic using the injected strategies.
-func (p *PaymentProcessor) ProcessOrder(ctx domain.OrderContext) error {
-	if ctx.Amount <= 0 {
-		return domain.ErrInvalidAmount
+// SetRegistry sets the registry used to resolve methods selected by name.
+func (p *PaymentProcessor) SetRegistry(registry *StrategyRegistry) {
+	p.registry = registry
+}
+
+// SetExchangeRates sets the provider used to convert the order amount
+// for payment methods that do not accept the order currency.
+func (p *PaymentProcessor) SetExchangeRates(rates domain.ExchangeRateProvider) {
+	p.rates = rates
+}
+
+// SetRetryPolicy sets how transient failures of each step are retried.
+func (p *PaymentProcessor) SetRetryPolicy(policy RetryPolicy) {
+	p.retry = policy
+}
+
+// SetTimeouts sets the time limit of each checkout step.
+func (p *PaymentProcessor) SetTimeouts(timeouts StepTimeouts) {
+	p.timeouts = timeouts
+}
+
+// SetIdempotencyStore enables idempotent processing of orders that carry an OrderID.
+func (p *PaymentProcessor) SetIdempotencyStore(store domain.IdempotencyStore) {
+	p.orders = store
+}
+
+// SetEventSink sets where checkout events are published. A nil sink disables them.
+func (p *PaymentProcessor) SetEventSink(sink domain.EventSink) {
+	p.events = sink
+}
+
+// UseConfig replaces the current strategies with the ones named in cfg.
+// Nothing changes if either name cannot be resolved.
+func (p *PaymentProcessor) UseConfig(cfg domain.CheckoutConfig) error {
+	payment, err := p.resolvePayment(cfg.Payment)
+	if err != nil {
+		return err
+	}
+	shipping, err := p.resolveShipping(cfg.Shipping)
+	if err != nil {
+		return err
+	}
+	p.payment = payment
+	p.shipping = shipping
+	return nil
+}
+
+// ProcessOrder executes the business logic using the injected strategies
+// and returns the payment receipt.
+// Each step runs under its own timeout and transient failures are retried
+// according to the retry policy. Cancelling ctx stops the checkout.
+// If shipping fails after the payment went through, the payment is refunded
+// and the shipping error is returned (joined with the refund error, if any).
+//
+// With an idempotency store set, an order whose OrderID was already processed
+// returns the original receipt without charging again. An order that failed
+// is released for retry unless its charge could not be refunded; that one
+// keeps returning domain.ErrChargeOutstanding.
+func (p *PaymentProcessor) ProcessOrder(ctx context.Context, order domain.OrderContext) (domain.Receipt, error) {
+	if !order.Amount.IsPositive() {
+		return domain.Receipt{}, domain.ErrInvalidAmount
+	}
+	if _, err := order.Amount.Currency.Digits(); err != nil {
+		return domain.Receipt{}, err
+	}
+
+	if order.Destination == "" {
+		return domain.Receipt{}, domain.ErrInvalidDestination
+	}
+
+	if p.orders == nil || order.OrderID == "" {
+		return p.checkout(ctx, order)
+	}
+	return p.checkoutOnce(ctx, order)
+}
+
+// checkoutOnce runs checkout at most once per OrderID.
+func (p *PaymentProcessor) checkoutOnce(ctx context.Context, order domain.OrderContext) (domain.Receipt, error) {
+	fingerprint := orderFingerprint(order)
+	rec, reserved, err := p.orders.Reserve(ctx, order.OrderID, fingerprint)
+	if err != nil {
+		return domain.Receipt{}, fmt.Errorf("idempotency: %w", err)
 	}
+	if !reserved {
+		switch {
+		case rec.Fingerprint != fingerprint:
+			return domain.Receipt{}, fmt.Errorf("%w: %q", domain.ErrIdempotencyKeyReused, order.OrderID)
+		case !rec.Done:
+			return domain.Receipt{}, fmt.Errorf("%w: %q", domain.ErrOrderInProgress, order.OrderID)
+		case rec.Failed:
+			return domain.Receipt{}, fmt.Errorf("%w: %q (transaction %s)", domain.ErrChargeOutstanding, order.OrderID, rec.Receipt.TransactionID)
+		}
+		p.logger.Log(fmt.Sprintf("--- Order %s Already Processed (Transaction: %s) ---", order.OrderID, rec.Receipt.TransactionID))
+		return rec.Receipt, nil
+	}
+
+	// Bookkeeping must finish even if the caller gives up now
+	storeCtx := context.WithoutCancel(ctx)
+	receipt, err := p.checkout(ctx, order)
+	var refundErr *domain.RefundError
+	if errors.As(err, &refundErr) {
+		// The customer is still charged: keep the key so a retry cannot charge again
+		if failErr := p.orders.Fail(storeCtx, order.OrderID, refundErr.Receipt); failErr != nil {
+			return domain.Receipt{}, errors.Join(err, fmt.Errorf("idempotency: %w", failErr))
+		}
+		return domain.Receipt{}, err
+	}
+	if err != nil {
+		if releaseErr := p.orders.Release(storeCtx, order.OrderID); releaseErr != nil {
+			return domain.Receipt{}, errors.Join(err, fmt.Errorf("idempotency: %w", releaseErr))
+		}
+		return domain.Receipt{}, err
+	}
+	if err := p.orders.Complete(storeCtx, order.OrderID, receipt); err != nil {
+		// The customer was charged; hand back the receipt along with the error
+		return receipt, fmt.Errorf("idempotency: order %s paid but not recorded: %w", order.OrderID, err)
+	}
+	return receipt, nil
+}
+
+// orderFingerprint summarizes what the customer asked for, including the
+// method configs so a different card or address counts as a different order.
+// It is hashed because the configs may hold payment details.
+func orderFingerprint(order domain.OrderContext) string {
+	// fmt prints maps with sorted keys, so equal configs give equal fingerprints
+	summary := fmt.Sprintf("%s|%s|%v|%v", order.Amount, order.Destination, order.Payment, order.Shipping)
+	sum := sha256.Sum256([]byte(summary))
+	return hex.EncodeToString(sum[:])
+}
 
-	if ctx.Destination == "" {
-		return domain.ErrInvalidDestination
+// checkout pays and ships a validated order.
+func (p *PaymentProcessor) checkout(ctx context.Context, order domain.OrderContext) (domain.Receipt, error) {
+	// Methods named on the order apply to this order only
+	payment, shipping := p.payment, p.shipping
+	var err error
+	if order.Payment.Name != "" {
+		if payment, err = p.resolvePayment(order.Payment); err != nil {
+			return domain.Receipt{}, err
+		}
+	}
+	if order.Shipping.Name != "" {
+		if shipping, err = p.resolveShipping(order.Shipping); err != nil {
+			return domain.Receipt{}, err
+		}
+	}
+
+	if payment == nil || shipping == nil {
+		return domain.Receipt{}, errors.New("payment or shipping strategy is not set")
 	}
 
-	if p.payment == nil || p.shipping == nil {
-		return errors.New("payment or shipping strategy is not set")
+	amount, err := chargeAmount(payment, order.Amount, p.rates)
+	if err != nil {
+		return domain.Receipt{}, err
 	}
 
+	event := domain.CheckoutEvent{OrderID: order.OrderID, Amount: amount, Destination: order.Destination}
 	p.logger.Log("--- Starting Payment Process ---")
+	if amount != order.Amount {
+		p.logger.Log(fmt.Sprintf("--- Converted %s to %s ---", order.Amount, amount))
+	}
+	p.publish(domain.EventPaymentStarted, event, nil)
 	// The usecase doesn't know *how* the payment is made, only *that* it is made.
-	if err := p.payment.Pay(ctx.Amount); err != nil {
+	var receipt domain.Receipt
+	err = p.step(ctx, "Payment", p.timeouts.Payment, payRetryable(payment), func(ctx context.Context) error {
+		var err error
+		receipt, err = payment.Pay(ctx, amount)
 		return err
+	})
+	if err != nil {
+		p.publish(domain.EventPaymentFailed, event, err)
+		return domain.Receipt{}, err
 	}
-	p.logger.Log("--- Payment Successful ---")
+	event.TransactionID, event.Method = receipt.TransactionID, receipt.Method
+	p.publish(domain.EventPaymentSucceeded, event, nil)
+	p.logger.Log(fmt.Sprintf("--- Payment Successful (Transaction: %s) ---", receipt.TransactionID))
 
 	p.logger.Log("--- Preparing Shipment ---")
-	if err := p.shipping.Ship(ctx.Destination); err != nil {
-		return err
+	err = p.step(ctx, "Shipment", p.timeouts.Shipping, domain.IsRetryable, func(ctx context.Context) error {
+		return shipping.Ship(ctx, order.Destination)
+	})
+	if err != nil {
+		p.logger.Log("--- Shipment Failed, Refunding Payment ---")
+		p.publish(domain.EventShipmentFailed, event, err)
+		// The customer must get the money back even if the checkout itself was cancelled
+		refundCtx := context.WithoutCancel(ctx)
+		refundErr := p.step(refundCtx, "Refund", p.timeouts.Refund, domain.IsRetryable, func(ctx context.Context) error {
+			return payment.Refund(ctx, receipt)
+		})
+		if refundErr != nil {
+			p.logger.Log("--- Refund Failed ---")
+			p.publish(domain.EventRefundFailed, event, refundErr)
+			return domain.Receipt{}, errors.Join(err, &domain.RefundError{Receipt: receipt, Err: refundErr})
+		}
+		p.logger.Log("--- Payment Refunded ---")
+		p.publish(domain.EventPaymentRefunded, event, nil)
+		return domain.Receipt{}, err
 	}
 	p.logger.Log("--- Shipment Scheduled ---")
-	return nil
+	p.publish(domain.EventShipmentScheduled, event, nil)
+	return receipt, nil
+}
+
+// publish sends an event of type t to the event sink, if one is set.
+func (p *PaymentProcessor) publish(t domain.CheckoutEventType, event domain.CheckoutEvent, err error) {
+	if p.events == nil {
+		return
+	}
+	event.Type = t
+	event.Err = err
+	event.Time = time.Now()
+	p.events.Publish(event)
+}
+
+// step runs fn under the step timeout with the retry policy,
+// retrying the errors retryable accepts.
+func (p *PaymentProcessor) step(ctx context.Context, name string, timeout time.Duration, retryable func(error) bool, fn func(ctx context.Context) error) error {
+	ctx, cancel := withTimeout(ctx, timeout)
+	defer cancel()
+
+	return retry(ctx, p.retry, retryable, fn, func(attempt int, wait time.Duration, err error) {
+		p.logger.Log(fmt.Sprintf("--- %s attempt %d failed (%v), retrying in %v ---", name, attempt, err, wait))
+	})
+}
+
+// chargeAmount converts amount into a currency the payment method accepts.
+// Methods that do not restrict currencies are charged the order amount as is.
+func chargeAmount(payment domain.PaymentMethod, amount domain.Money, rates domain.ExchangeRateProvider) (domain.Money, error) {
+	restricted, ok := payment.(domain.CurrencyRestricted)
+	if !ok {
+		return amount, nil
+	}
+	accepted := restricted.AcceptedCurrencies()
+	if slices.Contains(accepted, amount.Currency) || len(accepted) == 0 {
+		return amount, nil
+	}
+	if rates == nil {
+		return domain.Money{}, fmt.Errorf("%w: %s (accepted: %v, no exchange rates set)", domain.ErrUnsupportedCurrency, amount.Currency, accepted)
+	}
+	return domain.Convert(amount, accepted[0], rates)
+}
+
+func (p *PaymentProcessor) resolvePayment(sel domain.MethodSelection) (domain.PaymentMethod, error) {
+	if p.registry == nil {
+		return nil, ErrNoRegistry
+	}
+	return p.registry.Payment(sel)
+}
+
+func (p *PaymentProcessor) resolveShipping(sel domain.MethodSelection) (domain.ShippingMethod, error) {
+	if p.registry == nil {
+		return nil, ErrNoRegistry
+	}
+	return p.registry.Shipping(sel)
 }
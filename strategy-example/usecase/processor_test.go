@@ -1,7 +1,10 @@
 package usecase_test
 
 import (
+	"context"
 	"errors"
+	"math/big"
+	"slices"
 	"testing"
 
 	"strategy-example/domain"
@@ -10,24 +13,45 @@ import (
 
 // MockPaymentMethod is a mock implementation of domain.PaymentMethod
 type MockPaymentMethod struct {
-	PayFunc func(amount float64) error
+	PayFunc    func(ctx context.Context, amount domain.Money) (domain.Receipt, error)
+	RefundFunc func(ctx context.Context, receipt domain.Receipt) error
+	Idempotent bool // Reported by ChargesIdempotently
 }
 
-func (m *MockPaymentMethod) Pay(amount float64) error {
+func (m *MockPaymentMethod) Pay(ctx context.Context, amount domain.Money) (domain.Receipt, error) {
 	if m.PayFunc != nil {
-		return m.PayFunc(amount)
+		return m.PayFunc(ctx, amount)
+	}
+	return domain.Receipt{TransactionID: "mock-1", Amount: amount}, nil
+}
+
+func (m *MockPaymentMethod) Refund(ctx context.Context, receipt domain.Receipt) error {
+	if m.RefundFunc != nil {
+		return m.RefundFunc(ctx, receipt)
 	}
 	return nil
 }
 
+func (m *MockPaymentMethod) ChargesIdempotently() bool {
+	return m.Idempotent
+}
+
 // MockShippingMethod is a mock implementation of domain.ShippingMethod
 type MockShippingMethod struct {
-	ShipFunc func(destination string) error
+	QuoteFunc func(ctx context.Context, destination string, weightGrams int) (domain.Quote, error)
+	ShipFunc  func(ctx context.Context, destination string) error
+}
+
+func (m *MockShippingMethod) Quote(ctx context.Context, destination string, weightGrams int) (domain.Quote, error) {
+	if m.QuoteFunc != nil {
+		return m.QuoteFunc(ctx, destination, weightGrams)
+	}
+	return domain.Quote{}, nil
 }
 
-func (m *MockShippingMethod) Ship(destination string) error {
+func (m *MockShippingMethod) Ship(ctx context.Context, destination string) error {
 	if m.ShipFunc != nil {
-		return m.ShipFunc(destination)
+		return m.ShipFunc(ctx, destination)
 	}
 	return nil
 }
@@ -44,17 +68,21 @@ func (m *MockLogger) Log(message string) {
 }
 
 func TestPaymentProcessor_ProcessOrder(t *testing.T) {
+	usd100 := domain.NewMoney(10000, domain.USD)
+
 	tests := []struct {
 		name        string
-		amount      float64
+		amount      domain.Money
 		destination string
 		payErr      error
 		shipErr     error
+		refundErr   error
 		wantErr     error
+		wantRefund  bool
 	}{
 		{
 			name:        "Success",
-			amount:      100.0,
+			amount:      usd100,
 			destination: "Tokyo",
 			payErr:      nil,
 			shipErr:     nil,
@@ -62,7 +90,7 @@ func TestPaymentProcessor_ProcessOrder(t *testing.T) {
 		},
 		{
 			name:        "Invalid Amount",
-			amount:      -10.0,
+			amount:      domain.NewMoney(-1000, domain.USD),
 			destination: "Tokyo",
 			payErr:      nil,
 			shipErr:     nil,
@@ -70,7 +98,7 @@ func TestPaymentProcessor_ProcessOrder(t *testing.T) {
 		},
 		{
 			name:        "Invalid Destination",
-			amount:      100.0,
+			amount:      usd100,
 			destination: "",
 			payErr:      nil,
 			shipErr:     nil,
@@ -78,7 +106,7 @@ func TestPaymentProcessor_ProcessOrder(t *testing.T) {
 		},
 		{
 			name:        "Payment Failure",
-			amount:      100.0,
+			amount:      usd100,
 			destination: "Tokyo",
 			payErr:      errors.New("payment failed"),
 			shipErr:     nil,
@@ -86,23 +114,41 @@ func TestPaymentProcessor_ProcessOrder(t *testing.T) {
 		},
 		{
 			name:        "Shipping Failure",
-			amount:      100.0,
+			amount:      usd100,
 			destination: "Tokyo",
 			payErr:      nil,
 			shipErr:     errors.New("shipping failed"),
 			wantErr:     errors.New("shipping failed"),
+			wantRefund:  true,
+		},
+		{
+			name:        "Shipping Failure And Refund Failure",
+			amount:      usd100,
+			destination: "Tokyo",
+			shipErr:     errors.New("shipping failed"),
+			refundErr:   errors.New("gateway down"),
+			wantErr:     errors.New("shipping failed\nrefund mock-1: gateway down"),
+			wantRefund:  true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			var refunded []domain.Receipt
 			mockPay := &MockPaymentMethod{
-				PayFunc: func(amount float64) error {
-					return tt.payErr
+				PayFunc: func(ctx context.Context, amount domain.Money) (domain.Receipt, error) {
+					if tt.payErr != nil {
+						return domain.Receipt{}, tt.payErr
+					}
+					return domain.Receipt{TransactionID: "mock-1", Amount: amount}, nil
+				},
+				RefundFunc: func(ctx context.Context, receipt domain.Receipt) error {
+					refunded = append(refunded, receipt)
+					return tt.refundErr
 				},
 			}
 			mockShip := &MockShippingMethod{
-				ShipFunc: func(destination string) error {
+				ShipFunc: func(ctx context.Context, destination string) error {
 					return tt.shipErr
 				},
 			}
@@ -116,7 +162,7 @@ func TestPaymentProcessor_ProcessOrder(t *testing.T) {
 				Destination: tt.destination,
 			}
 
-			err := p.ProcessOrder(ctx)
+			receipt, err := p.ProcessOrder(context.Background(), ctx)
 
 			if tt.wantErr != nil {
 				if err == nil {
@@ -128,6 +174,199 @@ func TestPaymentProcessor_ProcessOrder(t *testing.T) {
 				if err != nil {
 					t.Errorf("expected no error, got %v", err)
 				}
+				if receipt.TransactionID != "mock-1" || receipt.Amount != tt.amount {
+					t.Errorf("unexpected receipt %+v", receipt)
+				}
+			}
+
+			if tt.wantRefund {
+				if len(refunded) != 1 || refunded[0].TransactionID != "mock-1" {
+					t.Errorf("expected one refund of mock-1, got %+v", refunded)
+				}
+			} else if len(refunded) != 0 {
+				t.Errorf("expected no refund, got %+v", refunded)
+			}
+		})
+	}
+}
+
+// MockRestrictedPayment only accepts the listed currencies.
+type MockRestrictedPayment struct {
+	MockPaymentMethod
+	Accepted []domain.Currency
+}
+
+func (m *MockRestrictedPayment) AcceptedCurrencies() []domain.Currency {
+	return m.Accepted
+}
+
+// MockRates returns a fixed rate for every pair.
+type MockRates struct {
+	RateValue *big.Rat
+}
+
+func (m *MockRates) Rate(from, to domain.Currency) (*big.Rat, error) {
+	return m.RateValue, nil
+}
+
+func TestPaymentProcessor_CurrencyConversion(t *testing.T) {
+	tests := []struct {
+		name       string
+		amount     domain.Money
+		accepted   []domain.Currency
+		rates      domain.ExchangeRateProvider
+		wantCharge domain.Money
+		wantErr    error
+	}{
+		{
+			name:       "Accepted Currency Is Charged As Is",
+			amount:     domain.NewMoney(1000, domain.JPY),
+			accepted:   []domain.Currency{domain.USD, domain.JPY},
+			wantCharge: domain.NewMoney(1000, domain.JPY),
+		},
+		{
+			name:       "Converted To First Accepted Currency",
+			amount:     domain.NewMoney(1000, domain.USD),
+			accepted:   []domain.Currency{domain.JPY},
+			rates:      &MockRates{RateValue: big.NewRat(150, 1)},
+			wantCharge: domain.NewMoney(1500, domain.JPY),
+		},
+		{
+			name:     "No Rates",
+			amount:   domain.NewMoney(1000, domain.USD),
+			accepted: []domain.Currency{domain.JPY},
+			wantErr:  domain.ErrUnsupportedCurrency,
+		},
+		{
+			name:    "Unknown Order Currency",
+			amount:  domain.NewMoney(1000, "XXX"),
+			wantErr: domain.ErrUnsupportedCurrency,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var charged []domain.Money
+			payment := &MockRestrictedPayment{Accepted: tt.accepted}
+			payment.PayFunc = func(ctx context.Context, amount domain.Money) (domain.Receipt, error) {
+				charged = append(charged, amount)
+				return domain.Receipt{TransactionID: "mock-1", Amount: amount}, nil
+			}
+
+			p := usecase.NewPaymentProcessor(payment, &MockShippingMethod{}, &MockLogger{})
+			if tt.rates != nil {
+				p.SetExchangeRates(tt.rates)
+			}
+
+			receipt, err := p.ProcessOrder(context.Background(), domain.OrderContext{Amount: tt.amount, Destination: "Tokyo"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ProcessOrder() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(charged) != 0 {
+					t.Errorf("expected no charge, got %v", charged)
+				}
+				return
+			}
+			if len(charged) != 1 || charged[0] != tt.wantCharge || receipt.Amount != tt.wantCharge {
+				t.Errorf("charged %v (receipt %v), want %v", charged, receipt.Amount, tt.wantCharge)
+			}
+		})
+	}
+}
+
+// MockEventSink records published events
+type MockEventSink struct {
+	Events []domain.CheckoutEvent
+}
+
+func (m *MockEventSink) Publish(event domain.CheckoutEvent) {
+	m.Events = append(m.Events, event)
+}
+
+func (m *MockEventSink) Types() []domain.CheckoutEventType {
+	var types []domain.CheckoutEventType
+	for _, e := range m.Events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func TestPaymentProcessor_Events(t *testing.T) {
+	errDeclined := errors.New("card declined")
+	errNoCarrier := errors.New("no carrier")
+	errGatewayDown := errors.New("gateway down")
+
+	tests := []struct {
+		name      string
+		payErr    error
+		shipErr   error
+		refundErr error
+		wantTypes []domain.CheckoutEventType
+		wantErrAt map[domain.CheckoutEventType]error
+	}{
+		{
+			name:      "Success",
+			wantTypes: []domain.CheckoutEventType{domain.EventPaymentStarted, domain.EventPaymentSucceeded, domain.EventShipmentScheduled},
+		},
+		{
+			name:      "Payment Failure",
+			payErr:    errDeclined,
+			wantTypes: []domain.CheckoutEventType{domain.EventPaymentStarted, domain.EventPaymentFailed},
+			wantErrAt: map[domain.CheckoutEventType]error{domain.EventPaymentFailed: errDeclined},
+		},
+		{
+			name:      "Shipping Failure",
+			shipErr:   errNoCarrier,
+			wantTypes: []domain.CheckoutEventType{domain.EventPaymentStarted, domain.EventPaymentSucceeded, domain.EventShipmentFailed, domain.EventPaymentRefunded},
+			wantErrAt: map[domain.CheckoutEventType]error{domain.EventShipmentFailed: errNoCarrier},
+		},
+		{
+			name:      "Shipping Failure And Refund Failure",
+			shipErr:   errNoCarrier,
+			refundErr: errGatewayDown,
+			wantTypes: []domain.CheckoutEventType{domain.EventPaymentStarted, domain.EventPaymentSucceeded, domain.EventShipmentFailed, domain.EventRefundFailed},
+			wantErrAt: map[domain.CheckoutEventType]error{domain.EventShipmentFailed: errNoCarrier, domain.EventRefundFailed: errGatewayDown},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := &MockPaymentMethod{
+				PayFunc: func(ctx context.Context, amount domain.Money) (domain.Receipt, error) {
+					if tt.payErr != nil {
+						return domain.Receipt{}, tt.payErr
+					}
+					return domain.Receipt{TransactionID: "mock-1", Amount: amount}, nil
+				},
+				RefundFunc: func(ctx context.Context, receipt domain.Receipt) error { return tt.refundErr },
+			}
+			shipping := &MockShippingMethod{
+				ShipFunc: func(ctx context.Context, destination string) error { return tt.shipErr },
+			}
+			sink := &MockEventSink{}
+			p := usecase.NewPaymentProcessor(payment, shipping, &MockLogger{})
+			p.SetEventSink(sink)
+
+			order := domain.OrderContext{OrderID: "order-1", Amount: domain.NewMoney(1000, domain.USD), Destination: "Tokyo"}
+			if _, err := p.ProcessOrder(context.Background(), order); (err != nil) != (tt.payErr != nil || tt.shipErr != nil) {
+				t.Fatalf("ProcessOrder() error = %v", err)
+			}
+
+			if !slices.Equal(sink.Types(), tt.wantTypes) {
+				t.Fatalf("events = %v, want %v", sink.Types(), tt.wantTypes)
+			}
+			for _, e := range sink.Events {
+				if e.OrderID != order.OrderID || e.Amount != order.Amount || e.Destination != order.Destination || e.Time.IsZero() {
+					t.Errorf("%s: unexpected event %+v", e.Type, e)
+				}
+				paid := e.Type != domain.EventPaymentStarted && e.Type != domain.EventPaymentFailed
+				if paid != (e.TransactionID == "mock-1") {
+					t.Errorf("%s: TransactionID = %q", e.Type, e.TransactionID)
+				}
+				if !errors.Is(e.Err, tt.wantErrAt[e.Type]) || (e.Err != nil && tt.wantErrAt[e.Type] == nil) {
+					t.Errorf("%s: Err = %v, want %v", e.Type, e.Err, tt.wantErrAt[e.Type])
+				}
 			}
 		})
 	}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+
+	"strategy-example/domain"
+)
+
+// StrategyRegistry maps method names to the factories that build them.
+// It lets callers pick strategies by name (from an order or a config file)
+// instead of constructing them directly.
+type StrategyRegistry struct {
+	payments  map[string]domain.PaymentFactory
+	shippings map[string]domain.ShippingFactory
+}
+
+// NewStrategyRegistry creates an empty registry.
+func NewStrategyRegistry() *StrategyRegistry {
+	return &StrategyRegistry{
+		payments:  make(map[string]domain.PaymentFactory),
+		shippings: make(map[string]domain.ShippingFactory),
+	}
+}
+
+// RegisterPayment adds a payment factory under name, replacing any previous one.
+func (r *StrategyRegistry) RegisterPayment(name string, factory domain.PaymentFactory) {
+	r.payments[name] = factory
+}
+
+// RegisterShipping adds a shipping factory under name, replacing any previous one.
+func (r *StrategyRegistry) RegisterShipping(name string, factory domain.ShippingFactory) {
+	r.shippings[name] = factory
+}
+
+// Payment builds the payment method selected by sel.
+func (r *StrategyRegistry) Payment(sel domain.MethodSelection) (domain.PaymentMethod, error) {
+	factory, ok := r.payments[sel.Name]
+	if !ok {
+		return nil, &domain.UnknownMethodError{Kind: domain.KindPayment, Name: sel.Name}
+	}
+	method, err := factory(sel.Config)
+	if err != nil {
+		return nil, fmt.Errorf("payment method %q: %w", sel.Name, err)
+	}
+	return method, nil
+}
+
+// Shipping builds the shipping method selected by sel.
+func (r *StrategyRegistry) Shipping(sel domain.MethodSelection) (domain.ShippingMethod, error) {
+	factory, ok := r.shippings[sel.Name]
+	if !ok {
+		return nil, &domain.UnknownMethodError{Kind: domain.KindShipping, Name: sel.Name}
+	}
+	method, err := factory(sel.Config)
+	if err != nil {
+		return nil, fmt.Errorf("shipping method %q: %w", sel.Name, err)
+	}
+	return method, nil
+}
+
+// PaymentNames returns the registered payment method names in sorted order.
+func (r *StrategyRegistry) PaymentNames() []string {
+	return sortedKeys(r.payments)
+}
+
+// ShippingNames returns the registered shipping method names in sorted order.
+func (r *StrategyRegistry) ShippingNames() []string {
+	return sortedKeys(r.shippings)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,195 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"strategy-example/domain"
+	"strategy-example/usecase"
+)
+
+func newTestRegistry(paid *[]string, shipped *[]string) *usecase.StrategyRegistry {
+	r := usecase.NewStrategyRegistry()
+	r.RegisterPayment("mock", func(cfg domain.MethodConfig) (domain.PaymentMethod, error) {
+		return &MockPaymentMethod{
+			PayFunc: func(ctx context.Context, amount domain.Money) (domain.Receipt, error) {
+				*paid = append(*paid, cfg["account"])
+				return domain.Receipt{Amount: amount}, nil
+			},
+		}, nil
+	})
+	r.RegisterPayment("broken", func(cfg domain.MethodConfig) (domain.PaymentMethod, error) {
+		return nil, errors.New("missing account")
+	})
+	r.RegisterShipping("mock", func(cfg domain.MethodConfig) (domain.ShippingMethod, error) {
+		return &MockShippingMethod{
+			ShipFunc: func(ctx context.Context, destination string) error {
+				*shipped = append(*shipped, destination)
+				return nil
+			},
+		}, nil
+	})
+	return r
+}
+
+func TestStrategyRegistry_Lookup(t *testing.T) {
+	var paid, shipped []string
+	r := newTestRegistry(&paid, &shipped)
+
+	if got, want := r.PaymentNames(), []string{"broken", "mock"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PaymentNames() = %v, want %v", got, want)
+	}
+	if got, want := r.ShippingNames(), []string{"mock"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ShippingNames() = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		name     string
+		lookup   func() error
+		wantKind string // non-empty when an UnknownMethodError is expected
+		wantErr  bool
+	}{
+		{
+			name: "Known Payment",
+			lookup: func() error {
+				_, err := r.Payment(domain.MethodSelection{Name: "mock"})
+				return err
+			},
+		},
+		{
+			name: "Unknown Payment",
+			lookup: func() error {
+				_, err := r.Payment(domain.MethodSelection{Name: "cash"})
+				return err
+			},
+			wantKind: domain.KindPayment,
+			wantErr:  true,
+		},
+		{
+			name: "Unknown Shipping",
+			lookup: func() error {
+				_, err := r.Shipping(domain.MethodSelection{Name: "drone"})
+				return err
+			},
+			wantKind: domain.KindShipping,
+			wantErr:  true,
+		},
+		{
+			name: "Factory Error",
+			lookup: func() error {
+				_, err := r.Payment(domain.MethodSelection{Name: "broken"})
+				return err
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.lookup()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var unknown *domain.UnknownMethodError
+			isUnknown := errors.As(err, &unknown)
+			if isUnknown != (tt.wantKind != "") {
+				t.Fatalf("UnknownMethodError = %v, want %v (err: %v)", isUnknown, tt.wantKind != "", err)
+			}
+			if isUnknown {
+				if unknown.Kind != tt.wantKind {
+					t.Errorf("Kind = %q, want %q", unknown.Kind, tt.wantKind)
+				}
+				if !errors.Is(err, domain.ErrUnknownMethod) {
+					t.Errorf("expected errors.Is(err, ErrUnknownMethod)")
+				}
+			}
+		})
+	}
+}
+
+func TestPaymentProcessor_SelectByName(t *testing.T) {
+	var paid, shipped []string
+	r := newTestRegistry(&paid, &shipped)
+	mockLogger := &MockLogger{}
+
+	defaultPay := &MockPaymentMethod{
+		PayFunc: func(ctx context.Context, amount domain.Money) (domain.Receipt, error) {
+			paid = append(paid, "default")
+			return domain.Receipt{Amount: amount}, nil
+		},
+	}
+	p := usecase.NewPaymentProcessor(defaultPay, &MockShippingMethod{}, mockLogger)
+	p.SetRegistry(r)
+
+	// The order picks its own payment method; the processor's default is untouched
+	_, err := p.ProcessOrder(context.Background(), domain.OrderContext{
+		Amount:      domain.NewMoney(10, domain.USD),
+		Destination: "Tokyo",
+		Payment:     domain.MethodSelection{Name: "mock", Config: domain.MethodConfig{"account": "alice"}},
+		Shipping:    domain.MethodSelection{Name: "mock"},
+	})
+	if err != nil {
+		t.Fatalf("ProcessOrder() error = %v", err)
+	}
+	if _, err := p.ProcessOrder(context.Background(), domain.OrderContext{Amount: domain.NewMoney(10, domain.USD), Destination: "Osaka"}); err != nil {
+		t.Fatalf("ProcessOrder() error = %v", err)
+	}
+	if want := []string{"alice", "default"}; !reflect.DeepEqual(paid, want) {
+		t.Errorf("paid = %v, want %v", paid, want)
+	}
+	if want := []string{"Tokyo"}; !reflect.DeepEqual(shipped, want) {
+		t.Errorf("shipped = %v, want %v", shipped, want)
+	}
+
+	// Unknown names fail before anything is charged
+	paid = nil
+	_, err = p.ProcessOrder(context.Background(), domain.OrderContext{
+		Amount:      domain.NewMoney(10, domain.USD),
+		Destination: "Tokyo",
+		Payment:     domain.MethodSelection{Name: "mock"},
+		Shipping:    domain.MethodSelection{Name: "drone"},
+	})
+	var unknown *domain.UnknownMethodError
+	if !errors.As(err, &unknown) || unknown.Name != "drone" {
+		t.Errorf("expected UnknownMethodError for %q, got %v", "drone", err)
+	}
+	if len(paid) != 0 {
+		t.Errorf("expected no payment, got %v", paid)
+	}
+}
+
+func TestPaymentProcessor_UseConfig(t *testing.T) {
+	var paid, shipped []string
+	r := newTestRegistry(&paid, &shipped)
+	p := usecase.NewPaymentProcessor(nil, nil, &MockLogger{})
+
+	cfg := domain.CheckoutConfig{
+		Payment:  domain.MethodSelection{Name: "mock", Config: domain.MethodConfig{"account": "bob"}},
+		Shipping: domain.MethodSelection{Name: "mock"},
+	}
+	if err := p.UseConfig(cfg); !errors.Is(err, usecase.ErrNoRegistry) {
+		t.Fatalf("expected ErrNoRegistry, got %v", err)
+	}
+
+	p.SetRegistry(r)
+	if err := p.UseConfig(domain.CheckoutConfig{Payment: cfg.Payment, Shipping: domain.MethodSelection{Name: "drone"}}); !errors.Is(err, domain.ErrUnknownMethod) {
+		t.Fatalf("expected ErrUnknownMethod, got %v", err)
+	}
+	// A failed UseConfig leaves the processor without strategies, as before
+	if _, err := p.ProcessOrder(context.Background(), domain.OrderContext{Amount: domain.NewMoney(1, domain.USD), Destination: "Tokyo"}); err == nil {
+		t.Fatal("expected error for unset strategies")
+	}
+
+	if err := p.UseConfig(cfg); err != nil {
+		t.Fatalf("UseConfig() error = %v", err)
+	}
+	if _, err := p.ProcessOrder(context.Background(), domain.OrderContext{Amount: domain.NewMoney(1, domain.USD), Destination: "Nagoya"}); err != nil {
+		t.Fatalf("ProcessOrder() error = %v", err)
+	}
+	if !reflect.DeepEqual(paid, []string{"bob"}) || !reflect.DeepEqual(shipped, []string{"Nagoya"}) {
+		t.Errorf("paid = %v, shipped = %v", paid, shipped)
+	}
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"strategy-example/domain"
+)
+
+// RetryPolicy retries calls that fail with a domain.ErrRetryable error,
+// waiting longer after each attempt (exponential backoff). Payments that
+// charge idempotently are also retried after a domain.ErrOutcomeUnknown error.
+type RetryPolicy struct {
+	MaxAttempts    int           // Total attempts including the first; values below 1 mean 1
+	InitialBackoff time.Duration // Wait after the first failure
+	MaxBackoff     time.Duration // Upper bound for a single wait; 0 means no bound
+	Multiplier     float64       // Growth factor per attempt; values below 1 mean 2
+}
+
+// DefaultRetryPolicy makes up to 3 attempts, waiting 100ms then 200ms.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+		Multiplier:     2,
+	}
+}
+
+// NoRetry makes a single attempt.
+func NoRetry() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+// Backoff returns the wait after the given failed attempt (1-based).
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	d := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		d *= multiplier
+		if p.MaxBackoff > 0 && d >= float64(p.MaxBackoff) {
+			return p.MaxBackoff
+		}
+	}
+	return time.Duration(d)
+}
+
+// StepTimeouts bounds each checkout step, retries included. Zero means no timeout.
+type StepTimeouts struct {
+	Payment  time.Duration
+	Shipping time.Duration
+	Refund   time.Duration
+}
+
+// withTimeout derives a context for one step.
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// payRetryable returns which Pay errors of payment may be retried.
+// A charge of unknown outcome is only repeated if it cannot charge twice.
+func payRetryable(payment domain.PaymentMethod) func(error) bool {
+	if idempotent, ok := payment.(domain.IdempotentPayment); ok && idempotent.ChargesIdempotently() {
+		return func(err error) bool {
+			return domain.IsRetryable(err) || domain.IsOutcomeUnknown(err)
+		}
+	}
+	return domain.IsRetryable
+}
+
+// retry calls fn until it succeeds, fails with an error retryable does not accept,
+// runs out of attempts, or ctx is done. onRetry is told about each failed attempt
+// that will be retried.
+func retry(ctx context.Context, policy RetryPolicy, retryable func(error) bool, fn func(ctx context.Context) error, onRetry func(attempt int, wait time.Duration, err error)) error {
+	attempts := max(policy.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || !retryable(err) {
+			return err
+		}
+		if attempt == attempts {
+			return fmt.Errorf("gave up after %d attempts: %w", attempts, err)
+		}
+
+		wait := policy.Backoff(attempt)
+		onRetry(attempt, wait, err)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w (last error: %w)", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
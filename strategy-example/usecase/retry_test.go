@@ -0,0 +1,155 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"strategy-example/domain"
+	"strategy-example/usecase"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := usecase.RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     350 * time.Millisecond,
+		Multiplier:     2,
+	}
+
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 350 * time.Millisecond, 350 * time.Millisecond}
+	for i, w := range want {
+		if got := policy.Backoff(i + 1); got != w {
+			t.Errorf("Backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+// fastRetry keeps the tests quick.
+var fastRetry = usecase.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Multiplier: 2}
+
+func TestPaymentProcessor_Retry(t *testing.T) {
+	errBusy := domain.Retryable(errors.New("gateway busy"))
+	errDeclined := errors.New("card declined")
+	errTimeout := domain.OutcomeUnknown(errors.New("gateway timeout"))
+
+	tests := []struct {
+		name         string
+		failures     []error // Returned by successive Pay calls before succeeding
+		idempotent   bool
+		wantAttempts int
+		wantErr      error
+	}{
+		{"Transient Failures Are Retried", []error{errBusy, errBusy}, false, 3, nil},
+		{"Unclassified Errors Are Not Retried", []error{errDeclined}, false, 1, errDeclined},
+		{"Permanent Wins Over Retryable", []error{domain.Permanent(errBusy)}, false, 1, domain.ErrPermanent},
+		{"Gives Up After Max Attempts", []error{errBusy, errBusy, errBusy}, false, 3, domain.ErrRetryable},
+		// The first charge may have gone through; only an idempotent payment may repeat it
+		{"Unknown Outcome Is Not Retried", []error{errTimeout}, false, 1, domain.ErrOutcomeUnknown},
+		{"Unknown Outcome Wins Over Retryable", []error{domain.Retryable(errTimeout)}, false, 1, domain.ErrOutcomeUnknown},
+		{"Unknown Outcome Retried For Idempotent Payment", []error{errTimeout, errBusy}, true, 3, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			payment := &MockPaymentMethod{
+				PayFunc: func(ctx context.Context, amount domain.Money) (domain.Receipt, error) {
+					attempts++
+					if attempts <= len(tt.failures) {
+						return domain.Receipt{}, tt.failures[attempts-1]
+					}
+					return domain.Receipt{TransactionID: "mock-1", Amount: amount}, nil
+				},
+				Idempotent: tt.idempotent,
+			}
+
+			p := usecase.NewPaymentProcessor(payment, &MockShippingMethod{}, &MockLogger{})
+			p.SetRetryPolicy(fastRetry)
+			_, err := p.ProcessOrder(context.Background(), domain.OrderContext{Amount: domain.NewMoney(100, domain.USD), Destination: "Tokyo"})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ProcessOrder() error = %v, want %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestPaymentProcessor_StepTimeout(t *testing.T) {
+	// Shipping hangs until its step times out; the payment must still be refunded
+	var refundCtxErr error
+	refunded := false
+	payment := &MockPaymentMethod{
+		RefundFunc: func(ctx context.Context, receipt domain.Receipt) error {
+			refunded = true
+			refundCtxErr = ctx.Err()
+			return nil
+		},
+	}
+	shipping := &MockShippingMethod{
+		ShipFunc: func(ctx context.Context, destination string) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+
+	p := usecase.NewPaymentProcessor(payment, shipping, &MockLogger{})
+	p.SetTimeouts(usecase.StepTimeouts{Shipping: 10 * time.Millisecond})
+
+	_, err := p.ProcessOrder(context.Background(), domain.OrderContext{Amount: domain.NewMoney(100, domain.USD), Destination: "Tokyo"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ProcessOrder() error = %v, want DeadlineExceeded", err)
+	}
+	if !refunded || refundCtxErr != nil {
+		t.Errorf("refunded = %v with ctx error %v, want a refund under a live context", refunded, refundCtxErr)
+	}
+}
+
+func TestPaymentProcessor_Cancel(t *testing.T) {
+	t.Run("Cancelled Before Payment", func(t *testing.T) {
+		paid := false
+		payment := &MockPaymentMethod{
+			PayFunc: func(ctx context.Context, amount domain.Money) (domain.Receipt, error) {
+				if err := ctx.Err(); err != nil {
+					return domain.Receipt{}, err
+				}
+				paid = true
+				return domain.Receipt{}, nil
+			},
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		p := usecase.NewPaymentProcessor(payment, &MockShippingMethod{}, &MockLogger{})
+		_, err := p.ProcessOrder(ctx, domain.OrderContext{Amount: domain.NewMoney(100, domain.USD), Destination: "Tokyo"})
+		if !errors.Is(err, context.Canceled) || paid {
+			t.Errorf("ProcessOrder() error = %v, paid = %v", err, paid)
+		}
+	})
+
+	t.Run("Cancelled During Backoff", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var logs []string
+		payment := &MockPaymentMethod{
+			PayFunc: func(_ context.Context, amount domain.Money) (domain.Receipt, error) {
+				cancel()
+				return domain.Receipt{}, domain.Retryable(errors.New("gateway busy"))
+			},
+		}
+
+		p := usecase.NewPaymentProcessor(payment, &MockShippingMethod{}, &MockLogger{LogFunc: func(m string) { logs = append(logs, m) }})
+		p.SetRetryPolicy(usecase.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour})
+		_, err := p.ProcessOrder(ctx, domain.OrderContext{Amount: domain.NewMoney(100, domain.USD), Destination: "Tokyo"})
+		if !errors.Is(err, context.Canceled) || !errors.Is(err, domain.ErrRetryable) {
+			t.Errorf("ProcessOrder() error = %v, want Canceled wrapping the last error", err)
+		}
+		if !strings.Contains(strings.Join(logs, "\n"), "Payment attempt 1 failed") {
+			t.Errorf("expected the retry to be logged, got %q", logs)
+		}
+	})
+}
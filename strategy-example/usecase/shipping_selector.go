@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"strategy-example/domain"
+)
+
+// ShippingOption is one shipping method together with its quote for a parcel.
+type ShippingOption struct {
+	Name   string
+	Method domain.ShippingMethod
+	Quote  domain.Quote
+	// ComparableCost is Quote.Cost converted into the selector's currency,
+	// so that quotes in different currencies can be compared.
+	ComparableCost domain.Money
+}
+
+// SelectionPolicy picks one option among quoted candidates.
+// It returns false when no option satisfies the policy.
+type SelectionPolicy func(options []ShippingOption) (ShippingOption, bool)
+
+// Cheapest picks the lowest cost, preferring the faster option on a tie.
+func Cheapest() SelectionPolicy {
+	return func(options []ShippingOption) (ShippingOption, bool) {
+		return best(options, func(a, b ShippingOption) bool {
+			if a.ComparableCost.Minor != b.ComparableCost.Minor {
+				return a.ComparableCost.Minor < b.ComparableCost.Minor
+			}
+			return a.Quote.ETA < b.Quote.ETA
+		})
+	}
+}
+
+// Fastest picks the shortest ETA, preferring the cheaper option on a tie.
+func Fastest() SelectionPolicy {
+	return func(options []ShippingOption) (ShippingOption, bool) {
+		return best(options, func(a, b ShippingOption) bool {
+			if a.Quote.ETA != b.Quote.ETA {
+				return a.Quote.ETA < b.Quote.ETA
+			}
+			return a.ComparableCost.Minor < b.ComparableCost.Minor
+		})
+	}
+}
+
+// CheapestWithin picks the cheapest option that arrives within deadline.
+func CheapestWithin(deadline time.Duration) SelectionPolicy {
+	return func(options []ShippingOption) (ShippingOption, bool) {
+		var inTime []ShippingOption
+		for _, o := range options {
+			if o.Quote.ETA <= deadline {
+				inTime = append(inTime, o)
+			}
+		}
+		return Cheapest()(inTime)
+	}
+}
+
+// best returns the first option no other option beats.
+func best(options []ShippingOption, less func(a, b ShippingOption) bool) (ShippingOption, bool) {
+	if len(options) == 0 {
+		return ShippingOption{}, false
+	}
+	chosen := options[0]
+	for _, o := range options[1:] {
+		if less(o, chosen) {
+			chosen = o
+		}
+	}
+	return chosen, true
+}
+
+type namedShipping struct {
+	name   string
+	method domain.ShippingMethod
+}
+
+// ShippingSelector quotes a parcel with every candidate shipping method
+// and picks one according to a SelectionPolicy.
+type ShippingSelector struct {
+	candidates []namedShipping
+	currency   domain.Currency
+	rates      domain.ExchangeRateProvider
+}
+
+// NewShippingSelector creates a selector comparing costs in currency.
+// rates converts quotes in other currencies and may be nil if all carriers
+// quote in the same currency.
+func NewShippingSelector(currency domain.Currency, rates domain.ExchangeRateProvider) *ShippingSelector {
+	return &ShippingSelector{
+		currency: currency,
+		rates:    rates,
+	}
+}
+
+// Add registers a candidate shipping method under name.
+func (s *ShippingSelector) Add(name string, method domain.ShippingMethod) {
+	s.candidates = append(s.candidates, namedShipping{name: name, method: method})
+}
+
+// AddRegistered adds every shipping method in the registry, built with
+// configs[name] (an empty config when absent).
+func (s *ShippingSelector) AddRegistered(registry *StrategyRegistry, configs map[string]domain.MethodConfig) error {
+	for _, name := range registry.ShippingNames() {
+		method, err := registry.Shipping(domain.MethodSelection{Name: name, Config: configs[name]})
+		if err != nil {
+			return err
+		}
+		s.Add(name, method)
+	}
+	return nil
+}
+
+// Quotes asks every candidate for a quote. Candidates that cannot quote
+// (e.g. the parcel is too heavy for them) are left out; their errors are
+// returned only when no candidate could quote at all.
+func (s *ShippingSelector) Quotes(ctx context.Context, destination string, weightGrams int) ([]ShippingOption, error) {
+	var options []ShippingOption
+	var errs []error
+	for _, c := range s.candidates {
+		quote, err := c.method.Quote(ctx, destination, weightGrams)
+		if err == nil {
+			var cost domain.Money
+			if cost, err = s.comparableCost(quote.Cost); err == nil {
+				options = append(options, ShippingOption{Name: c.name, Method: c.method, Quote: quote, ComparableCost: cost})
+				continue
+			}
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+	}
+
+	if len(options) == 0 {
+		if len(errs) == 0 {
+			return nil, domain.ErrNoShippingOption
+		}
+		return nil, fmt.Errorf("%w: %w", domain.ErrNoShippingOption, errors.Join(errs...))
+	}
+	return options, nil
+}
+
+// Select quotes the parcel and returns the option chosen by policy.
+func (s *ShippingSelector) Select(ctx context.Context, destination string, weightGrams int, policy SelectionPolicy) (ShippingOption, error) {
+	options, err := s.Quotes(ctx, destination, weightGrams)
+	if err != nil {
+		return ShippingOption{}, err
+	}
+	chosen, ok := policy(options)
+	if !ok {
+		return ShippingOption{}, fmt.Errorf("%w: no quote satisfies the policy", domain.ErrNoShippingOption)
+	}
+	return chosen, nil
+}
+
+func (s *ShippingSelector) comparableCost(cost domain.Money) (domain.Money, error) {
+	if cost.Currency == s.currency {
+		return cost, nil
+	}
+	if s.rates == nil {
+		return domain.Money{}, fmt.Errorf("%w: %s quote with no exchange rates set", domain.ErrCurrencyMismatch, cost.Currency)
+	}
+	return domain.Convert(cost, s.currency, s.rates)
+}
@@ -0,0 +1,132 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"strategy-example/domain"
+	"strategy-example/usecase"
+)
+
+const day = 24 * time.Hour
+
+// quoting returns a shipping mock that always offers the given quote.
+func quoting(cost domain.Money, eta time.Duration) *MockShippingMethod {
+	return &MockShippingMethod{
+		QuoteFunc: func(ctx context.Context, destination string, weightGrams int) (domain.Quote, error) {
+			return domain.Quote{Carrier: "mock", Cost: cost, ETA: eta}, nil
+		},
+	}
+}
+
+func failing(err error) *MockShippingMethod {
+	return &MockShippingMethod{
+		QuoteFunc: func(ctx context.Context, destination string, weightGrams int) (domain.Quote, error) {
+			return domain.Quote{}, err
+		},
+	}
+}
+
+func TestShippingSelector_Select(t *testing.T) {
+	usd := func(minor int64) domain.Money { return domain.NewMoney(minor, domain.USD) }
+
+	newSelector := func() *usecase.ShippingSelector {
+		s := usecase.NewShippingSelector(domain.USD, &MockRates{RateValue: big.NewRat(1, 100)}) // 1 JPY = 0.01 USD
+		s.Add("economy", quoting(usd(500), 7*day))
+		s.Add("standard", quoting(domain.NewMoney(800, domain.JPY), 3*day)) // 8.00 USD
+		s.Add("express", quoting(usd(2000), 1*day))
+		s.Add("courier", quoting(usd(2500), 1*day))
+		s.Add("freight", failing(domain.ErrParcelTooHeavy))
+		return s
+	}
+
+	tests := []struct {
+		name     string
+		policy   usecase.SelectionPolicy
+		wantName string
+		wantErr  error
+	}{
+		{"Cheapest", usecase.Cheapest(), "economy", nil},
+		{"Fastest Prefers Cheaper On Tie", usecase.Fastest(), "express", nil},
+		{"Cheapest Within 3 Days Converts Currency", usecase.CheapestWithin(3 * day), "standard", nil},
+		{"Cheapest Within 1 Day", usecase.CheapestWithin(day), "express", nil},
+		{"Nothing Within Deadline", usecase.CheapestWithin(time.Hour), "", domain.ErrNoShippingOption},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newSelector().Select(context.Background(), "Tokyo", 1200, tt.policy)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Select() error = %v, want %v", err, tt.wantErr)
+			}
+			if got.Name != tt.wantName {
+				t.Errorf("Select() = %q, want %q", got.Name, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestShippingSelector_Quotes(t *testing.T) {
+	t.Run("Failed Quotes Are Skipped", func(t *testing.T) {
+		s := usecase.NewShippingSelector(domain.USD, nil)
+		s.Add("ok", quoting(domain.NewMoney(100, domain.USD), day))
+		s.Add("too heavy", failing(domain.ErrParcelTooHeavy))
+		s.Add("other currency", quoting(domain.NewMoney(100, domain.EUR), day)) // No rates set
+
+		options, err := s.Quotes(context.Background(), "Tokyo", 1000)
+		if err != nil {
+			t.Fatalf("Quotes() error = %v", err)
+		}
+		if len(options) != 1 || options[0].Name != "ok" {
+			t.Errorf("Quotes() = %+v, want only %q", options, "ok")
+		}
+	})
+
+	t.Run("All Quotes Fail", func(t *testing.T) {
+		s := usecase.NewShippingSelector(domain.USD, nil)
+		s.Add("too heavy", failing(domain.ErrParcelTooHeavy))
+
+		_, err := s.Quotes(context.Background(), "Tokyo", 1000)
+		if !errors.Is(err, domain.ErrNoShippingOption) || !errors.Is(err, domain.ErrParcelTooHeavy) {
+			t.Errorf("Quotes() error = %v, want ErrNoShippingOption wrapping ErrParcelTooHeavy", err)
+		}
+	})
+
+	t.Run("No Candidates", func(t *testing.T) {
+		_, err := usecase.NewShippingSelector(domain.USD, nil).Quotes(context.Background(), "Tokyo", 1000)
+		if !errors.Is(err, domain.ErrNoShippingOption) {
+			t.Errorf("Quotes() error = %v, want ErrNoShippingOption", err)
+		}
+	})
+}
+
+func TestShippingSelector_AddRegistered(t *testing.T) {
+	r := usecase.NewStrategyRegistry()
+	r.RegisterShipping("flat", func(cfg domain.MethodConfig) (domain.ShippingMethod, error) {
+		cost, err := domain.ParseMoney(cfg["price"], domain.USD)
+		if err != nil {
+			return nil, err
+		}
+		return quoting(cost, 2*day), nil
+	})
+
+	s := usecase.NewShippingSelector(domain.USD, nil)
+	if err := s.AddRegistered(r, nil); err == nil {
+		t.Error("expected factory error without config")
+	}
+
+	s = usecase.NewShippingSelector(domain.USD, nil)
+	if err := s.AddRegistered(r, map[string]domain.MethodConfig{"flat": {"price": "3.50"}}); err != nil {
+		t.Fatalf("AddRegistered() error = %v", err)
+	}
+	got, err := s.Select(context.Background(), "Tokyo", 500, usecase.Cheapest())
+	if err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+	if got.Name != "flat" || got.Quote.Cost != domain.NewMoney(350, domain.USD) {
+		t.Errorf("Select() = %+v", got)
+	}
+}